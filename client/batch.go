@@ -0,0 +1,157 @@
+package client
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// exportBatchSize is the number of content bytes requested per ExportBatch
+// or GetFiles query.
+const exportBatchSize = 16 << 10
+
+// getFilesPathsPerQuery is the number of paths sent per GetFiles query.
+const getFilesPathsPerQuery = 100
+
+// ExportedFile is a file of a commit as paged by Repository.ExportBatch.
+type ExportedFile struct {
+	Path    string
+	Mode    FileMode
+	Content []byte
+}
+
+// ExportFiles pages through Repository.ExportBatch and returns the commit
+// ref names at address with its files in tree order. An unknown ref gives
+// an empty commit hash and no files.
+func (c *Client) ExportFiles(address, ref string) (string, []ExportedFile, error) {
+	batches := &fileBatches{}
+	err := batches.fetch(c, func(cursor string) string {
+		return fmt.Sprintf("%s.ExportBatch(%q, %q, %d)", addressExpr(address), ref, cursor, exportBatchSize)
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to export files: %w", err)
+	}
+	return batches.commit, batches.files, nil
+}
+
+// GetFiles returns the files at paths in the commit ref names, HEAD when ref
+// is empty, in the order of paths, and the paths that are not files of the
+// commit. Every query reads the commit of the first one.
+func (c *Client) GetFiles(address, ref string, paths []string) (string, []ExportedFile, []string, error) {
+	batches := &fileBatches{commit: ref}
+	for start := 0; start == 0 || start < len(paths); start += getFilesPathsPerQuery {
+		group := paths[start:min(start+getFilesPathsPerQuery, len(paths))]
+		quoted := make([]string, len(group))
+		for i, path := range group {
+			quoted[i] = strconv.Quote(path)
+		}
+
+		err := batches.fetch(c, func(cursor string) string {
+			return fmt.Sprintf("%s.GetFiles(%q, []string{%s}, %d, %q)",
+				addressExpr(address), batches.commit, strings.Join(quoted, ", "), exportBatchSize, cursor)
+		})
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("failed to get files: %w", err)
+		}
+		if batches.commit == "" {
+			break
+		}
+	}
+	return batches.commit, batches.files, batches.missing, nil
+}
+
+// fileBatches collects the files of ExportBatch and GetFiles batches.
+type fileBatches struct {
+	commit  string
+	files   []ExportedFile
+	sizes   map[int]int
+	missing []string
+}
+
+// fetch runs the query built for each cursor until a batch has no next line.
+func (f *fileBatches) fetch(c *Client, query func(cursor string) string) error {
+	if f.sizes == nil {
+		f.sizes = make(map[int]int)
+	}
+
+	cursor := ""
+	for {
+		data, err := c.QueryString(query(cursor))
+		if err != nil {
+			return err
+		}
+		if data == "" {
+			f.commit = ""
+			return nil
+		}
+
+		cursor = ""
+		for _, line := range strings.Split(data, "\n") {
+			if line == "" {
+				continue
+			}
+
+			fields := SplitEscaped(line, '|')
+			switch {
+			case fields[0] == "commit" && len(fields) == 2:
+				f.commit = fields[1]
+			case fields[0] == "next" && len(fields) == 2:
+				cursor = fields[1]
+			case fields[0] == "missing" && len(fields) == 2:
+				f.missing = append(f.missing, fields[1])
+			case fields[0] == "file" && len(fields) == 6:
+				f.files, err = appendExportedChunk(f.files, f.sizes, fields[1:])
+				if err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("invalid batch line %q", line)
+			}
+		}
+
+		if cursor == "" {
+			break
+		}
+	}
+
+	for i, file := range f.files {
+		if len(file.Content) != f.sizes[i] {
+			return fmt.Errorf("download of '%s' stopped at %d of %d bytes", file.Path, len(file.Content), f.sizes[i])
+		}
+	}
+	return nil
+}
+
+// appendExportedChunk adds the "path|mode|size|offset|base64" chunk of a
+// file line to files: a new file at offset 0, else the rest of the last one.
+func appendExportedChunk(files []ExportedFile, sizes map[int]int, fields []string) ([]ExportedFile, error) {
+	mode, err := strconv.ParseUint(fields[1], 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mode %q for '%s'", fields[1], fields[0])
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid size %q for '%s'", fields[2], fields[0])
+	}
+	offset, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid offset %q for '%s'", fields[3], fields[0])
+	}
+	chunk, err := base64.StdEncoding.DecodeString(fields[4])
+	if err != nil {
+		return nil, fmt.Errorf("invalid content for '%s': %w", fields[0], err)
+	}
+
+	if offset == 0 {
+		sizes[len(files)] = size
+		return append(files, ExportedFile{Path: fields[0], Mode: FileMode(mode), Content: chunk}), nil
+	}
+
+	last := len(files) - 1
+	if last < 0 || files[last].Path != fields[0] || len(files[last].Content) != offset {
+		return nil, fmt.Errorf("unexpected chunk of '%s' at offset %d", fields[0], offset)
+	}
+	files[last].Content = append(files[last].Content, chunk...)
+	return files, nil
+}
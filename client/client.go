@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -9,10 +10,19 @@ import (
 
 type Client struct {
 	config *Config
+	stdin  io.Reader
+	stdout io.Writer
 }
 
 func NewClient(cfg *Config) *Client {
-	return &Client{config: cfg}
+	return &Client{config: cfg, stdin: os.Stdin, stdout: os.Stdout}
+}
+
+// SetIO redirects the terminal of gnokey transactions, for callers whose
+// own stdin and stdout are not a terminal.
+func (c *Client) SetIO(stdin io.Reader, stdout io.Writer) {
+	c.stdin = stdin
+	c.stdout = stdout
 }
 
 func (c *Client) QueryRaw(expression string) (string, error) {
@@ -86,8 +96,8 @@ func (c *Client) Run(gnoCode string) error {
 		c.config.Account,
 		tmpFile)
 
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
+	cmd.Stdin = c.stdin
+	cmd.Stdout = c.stdout
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
@@ -97,58 +107,6 @@ func (c *Client) Run(gnoCode string) error {
 	return nil
 }
 
-func (c *Client) RunQuery(realmPath string, expression string) ([]byte, error) {
-	parts := strings.Split(realmPath, "/")
-	packageAlias := parts[len(parts)-1]
-
-	queryExpression := strings.Replace(expression, packageAlias+".", realmPath+".", 1)
-
-	if strings.Contains(expression, ".Repository.Pull(") {
-		return c.QueryFileInChunks(queryExpression)
-	}
-
-	return c.QueryDirectInChunks(queryExpression)
-}
-
-func (c *Client) QueryFileInChunks(expression string) ([]byte, error) {
-	sizeQuery := strings.Replace(expression, "Repository.Pull(", "Repository.GetFileSize(", 1)
-	sizeOutput, err := c.QueryEval(sizeQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get file size: %w", err)
-	}
-
-	sizeOutput = strings.TrimPrefix(sizeOutput, "data: ")
-	var size int
-	if _, err := fmt.Sscanf(sizeOutput, "(%d int)", &size); err != nil {
-		return nil, fmt.Errorf("failed to parse file size: %w", err)
-	}
-
-	if size < 0 {
-		return nil, fmt.Errorf("file not found")
-	}
-
-	if size == 0 {
-		return []byte{}, nil
-	}
-
-	var content []byte
-	chunkSize := 200
-	for offset := 0; offset < size; offset += chunkSize {
-		chunkQuery := strings.Replace(expression, "Repository.Pull(", "Repository.GetFileChunk(", 1)
-		chunkQuery = strings.Replace(chunkQuery, ")", fmt.Sprintf(", %d, %d)", offset, chunkSize), 1)
-
-		chunkOutput, err := c.QueryEval(chunkQuery)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get chunk at offset %d: %w", offset, err)
-		}
-
-		chunk := extractStringFromQuery(chunkOutput)
-		content = append(content, []byte(chunk)...)
-	}
-
-	return content, nil
-}
-
 func (c *Client) QueryEval(expression string) (string, error) {
 	cmd := exec.Command("gnokey", "query", "vm/qeval",
 		"-data", expression,
@@ -162,6 +120,17 @@ func (c *Client) QueryEval(expression string) (string, error) {
 	return extractDataLine(string(output))
 }
 
+// QueryString evaluates an expression returning a string and unquotes the
+// result.
+func (c *Client) QueryString(expression string) (string, error) {
+	output, err := c.QueryEval(expression)
+	if err != nil {
+		return "", err
+	}
+
+	return extractStringFromQuery(output), nil
+}
+
 func extractStringFromQuery(output string) string {
 	output = strings.TrimPrefix(output, "data: ")
 
@@ -208,15 +177,6 @@ func extractStringFromQuery(output string) string {
 	return result.String()
 }
 
-func (c *Client) QueryDirectInChunks(expression string) ([]byte, error) {
-	output, err := c.QueryEval(expression)
-	if err != nil {
-		return nil, err
-	}
-
-	return []byte(output), nil
-}
-
 func extractTransactionOutput(output string) string {
 	lines := strings.Split(output, "\n")
 	var contentLines []string
@@ -0,0 +1,347 @@
+// Command git-remote-gnit is a git remote helper for gnit realms. With it on
+// the PATH, git talks to a realm repository directly:
+//
+//	git clone gnit::gno.land/r/demo/myrepo
+//	git clone gnit::gno.land/r/team/hub#api
+//	git push
+//
+// Fetches import the realm history with fast-import streams; pushes replay
+// new commits of the realm's current branch with Repository.ImportCommit.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	config "github.com/gnoverse/gnit"
+	gnokey "github.com/gnoverse/gnit"
+)
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Fprintln(os.Stderr, "Usage: git-remote-gnit <remote> <realm-path>")
+		os.Exit(1)
+	}
+
+	cfg, err := config.DefaultConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	address := strings.TrimPrefix(os.Args[2], "gnit://")
+	cfg.RealmPath, cfg.Repository = config.SplitAddress(address)
+
+	client := gnokey.NewClient(cfg)
+	client.SetIO(terminal(), os.Stderr)
+
+	h, err := newHelper(client, address, os.Args[1], os.Getenv("GIT_DIR"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := h.run(bufio.NewReader(os.Stdin), os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// terminal returns the controlling terminal for gnokey's password prompt,
+// since stdin is the remote helper protocol.
+func terminal() io.Reader {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return strings.NewReader("")
+	}
+	return tty
+}
+
+type helper struct {
+	client   *gnokey.Client
+	address  string
+	prefix   string
+	gitMarks string
+	marks    *marks
+}
+
+func newHelper(client *gnokey.Client, address, remote, gitDir string) (*helper, error) {
+	if gitDir == "" {
+		gitDir = ".git"
+	}
+
+	dir := filepath.Join(gitDir, "gnit", remote)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create '%s': %w", dir, err)
+	}
+
+	gitMarks := filepath.Join(dir, "git.marks")
+	if _, err := os.Stat(gitMarks); os.IsNotExist(err) {
+		if err := os.WriteFile(gitMarks, nil, 0644); err != nil {
+			return nil, fmt.Errorf("failed to create '%s': %w", gitMarks, err)
+		}
+	}
+
+	m, err := loadMarks(filepath.Join(dir, "gnit.marks"), gitMarks)
+	if err != nil {
+		return nil, err
+	}
+
+	return &helper{
+		client:   client,
+		address:  address,
+		prefix:   "refs/gnit/" + remote + "/heads/",
+		gitMarks: gitMarks,
+		marks:    m,
+	}, nil
+}
+
+func (h *helper) run(in *bufio.Reader, out io.Writer) error {
+	for {
+		line, err := in.ReadString('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "":
+			return nil
+		case line == "capabilities":
+			fmt.Fprintf(out, "import\nexport\nrefspec refs/heads/*:%s*\n", h.prefix)
+			fmt.Fprintf(out, "*import-marks %s\n*export-marks %s\n\n", h.gitMarks, h.gitMarks)
+		case line == "list" || line == "list for-push":
+			if err := h.list(out); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "import "):
+			refs, err := readBatch(in, line)
+			if err != nil {
+				return err
+			}
+			if err := h.importRefs(out, refs); err != nil {
+				return err
+			}
+		case line == "export":
+			if err := h.export(in, out); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "option "):
+			fmt.Fprintln(out, "unsupported")
+		default:
+			return fmt.Errorf("unsupported command %q", line)
+		}
+	}
+}
+
+// readBatch collects the refs of a batch of import commands, which ends
+// with a blank line.
+func readBatch(in *bufio.Reader, first string) ([]string, error) {
+	refs := []string{strings.TrimPrefix(first, "import ")}
+
+	for {
+		line, err := in.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		if line == "" {
+			return refs, nil
+		}
+		refs = append(refs, strings.TrimPrefix(line, "import "))
+	}
+}
+
+func (h *helper) list(out io.Writer) error {
+	refs, err := h.client.ListRefs(h.address)
+	if err != nil {
+		return err
+	}
+
+	branch, err := h.client.CurrentBranch(h.address)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		fmt.Fprintf(out, "? refs/heads/%s\n", ref.Name)
+	}
+	if len(refs) > 0 {
+		fmt.Fprintf(out, "@refs/heads/%s HEAD\n", branch)
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+func (h *helper) importRefs(out io.Writer, names []string) error {
+	refs, err := h.client.ListRefs(h.address)
+	if err != nil {
+		return err
+	}
+
+	var selected []gnokey.RemoteRef
+	for _, ref := range refs {
+		for _, name := range names {
+			if name == "refs/heads/"+ref.Name {
+				selected = append(selected, ref)
+				break
+			}
+		}
+	}
+
+	fw := gnokey.NewFastImportWriter(out)
+	fw.ReserveMarks(h.marks.last)
+	for mark, hash := range h.marks.hashes {
+		fw.SetCommitMark(hash, mark)
+	}
+
+	fw.Feature("done")
+	fw.Feature("import-marks-if-exists=" + h.gitMarks)
+	fw.Feature("export-marks=" + h.gitMarks)
+
+	count, err := h.client.WriteHistory(fw, h.address, selected, h.prefix)
+	if err != nil {
+		return err
+	}
+
+	fw.Done()
+	if err := fw.Flush(); err != nil {
+		return err
+	}
+
+	for hash, mark := range fw.CommitMarks() {
+		h.marks.set(mark, hash)
+	}
+	fmt.Fprintf(os.Stderr, "Fetched %d new commit(s) from %s\n", count, h.address)
+	return h.marks.save()
+}
+
+func (h *helper) export(in *bufio.Reader, out io.Writer) error {
+	stream, err := gnokey.ParseFastExport(in)
+	if err != nil {
+		return fmt.Errorf("failed to parse pushed history: %w", err)
+	}
+
+	branch, err := h.client.CurrentBranch(h.address)
+	if err != nil {
+		return err
+	}
+
+	refs, err := h.client.ListRefs(h.address)
+	if err != nil {
+		return err
+	}
+
+	head := ""
+	for _, ref := range refs {
+		if ref.Name == branch {
+			head = ref.Hash
+		}
+	}
+
+	for _, ref := range pushedRefs(stream) {
+		if ref != "refs/heads/"+branch {
+			fmt.Fprintf(out, "error %s only the current branch '%s' can be pushed\n", ref, branch)
+			continue
+		}
+
+		if reason := h.push(stream, ref, head); reason != "" {
+			fmt.Fprintf(out, "error %s %s\n", ref, reason)
+			continue
+		}
+		fmt.Fprintf(out, "ok %s\n", ref)
+	}
+	fmt.Fprintln(out)
+
+	return h.marks.save()
+}
+
+// pushedRefs lists the refs a fast-export stream updates, in order.
+func pushedRefs(stream *gnokey.FastExportStream) []string {
+	var refs []string
+	seen := make(map[string]bool)
+
+	add := func(ref string) {
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, commit := range stream.Commits {
+		add(commit.Ref)
+	}
+	for ref := range stream.Resets {
+		add(ref)
+	}
+	return refs
+}
+
+// push replays the new commits of ref on top of head and returns why it
+// failed, or "" on success.
+func (h *helper) push(stream *gnokey.FastExportStream, ref, head string) string {
+	commits, err := stream.ReplayRef(ref)
+	if err != nil {
+		return err.Error()
+	}
+
+	base := stream.Base(commits)
+	if len(commits) == 0 {
+		base = stream.Resets[ref]
+	}
+
+	if h.marks.hash(base) != head {
+		return "non-fast-forward (fetch and rebase first)"
+	}
+	if len(commits) == 0 {
+		return ""
+	}
+
+	limits, err := h.client.Limits(h.address)
+	if err != nil {
+		return err.Error()
+	}
+	if err := limits.CheckReplay(commits); err != nil {
+		return "exceeds the repository limits: " + err.Error()
+	}
+
+	batches := gnokey.BatchReplayCommits(commits)
+	for n, batch := range batches {
+		fmt.Fprintf(os.Stderr, "Transaction %d/%d: %d commit(s)\n", n+1, len(batches), len(batch))
+
+		gnoCode := gnokey.GenerateImportCode(h.address, batch)
+		if err := h.client.Run(gnoCode); err != nil {
+			return err.Error()
+		}
+	}
+
+	pushed, err := h.client.LogPage(h.address, strings.TrimPrefix(ref, "refs/heads/"), 0, len(commits))
+	if err != nil {
+		return err.Error()
+	}
+	if len(pushed) != len(commits) || !hasParent(pushed[len(pushed)-1], head) {
+		return "realm history changed during push"
+	}
+
+	for i, commit := range commits {
+		mark, ok := parseMark(commit.SourceRef)
+		if ok {
+			h.marks.set(mark, pushed[len(pushed)-1-i].Hash)
+		}
+	}
+	return ""
+}
+
+func hasParent(commit *gnokey.RemoteCommit, parent string) bool {
+	if parent == "" {
+		return len(commit.Parents) == 0
+	}
+	return len(commit.Parents) > 0 && commit.Parents[0] == parent
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// marks maps the commit marks of git's marks file to gnit commit hashes, so
+// that later fetches and pushes only transfer new commits. Blob marks are
+// not kept: git fast-export drops them from the marks file.
+type marks struct {
+	path   string
+	hashes map[int]string
+	last   int
+}
+
+// loadMarks reads the gnit marks at path. The last mark also accounts for
+// the marks git allocated in gitMarksPath while exporting.
+func loadMarks(path, gitMarksPath string) (*marks, error) {
+	m := &marks{path: path, hashes: make(map[int]string)}
+
+	err := readMarkLines(path, func(mark int, hash string) {
+		m.hashes[mark] = hash
+		if mark > m.last {
+			m.last = mark
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readMarkLines(gitMarksPath, func(mark int, _ string) {
+		if mark > m.last {
+			m.last = mark
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func readMarkLines(path string, fn func(mark int, value string)) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open marks: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		mark, ok := parseMark(fields[0])
+		if !ok {
+			return fmt.Errorf("invalid mark %q in %s", fields[0], path)
+		}
+		fn(mark, fields[1])
+	}
+
+	return scanner.Err()
+}
+
+func parseMark(ref string) (int, bool) {
+	if !strings.HasPrefix(ref, ":") {
+		return 0, false
+	}
+
+	mark, err := strconv.Atoi(ref[1:])
+	if err != nil || mark <= 0 {
+		return 0, false
+	}
+	return mark, true
+}
+
+// hash returns the gnit hash of a ":<mark>" reference, or "".
+func (m *marks) hash(ref string) string {
+	mark, ok := parseMark(ref)
+	if !ok {
+		return ""
+	}
+	return m.hashes[mark]
+}
+
+func (m *marks) set(mark int, hash string) {
+	m.hashes[mark] = hash
+	if mark > m.last {
+		m.last = mark
+	}
+}
+
+func (m *marks) save() error {
+	numbers := make([]int, 0, len(m.hashes))
+	for mark := range m.hashes {
+		numbers = append(numbers, mark)
+	}
+	sort.Ints(numbers)
+
+	var b strings.Builder
+	for _, mark := range numbers {
+		fmt.Fprintf(&b, ":%d %s\n", mark, m.hashes[mark])
+	}
+
+	if err := os.WriteFile(m.path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to save marks: %w", err)
+	}
+	return nil
+}
@@ -75,7 +75,7 @@ func (a *Add) Execute(paths []string) error {
 }
 
 func (a *Add) collectFiles(path string, matcher *ignore.Matcher, result map[string]bool) error {
-	info, err := os.Stat(path)
+	info, err := os.Lstat(path)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	config "github.com/gnoverse/gnit"
+	gnokey "github.com/gnoverse/gnit"
+)
+
+type Checks struct {
+	client      *gnokey.Client
+	config      *config.Config
+	description string
+	url         string
+}
+
+func NewChecks(client *gnokey.Client, cfg *config.Config) *Checks {
+	return &Checks{
+		client: client,
+		config: cfg,
+	}
+}
+
+func (c *Checks) SetDescription(description string) {
+	c.description = description
+}
+
+func (c *Checks) SetURL(url string) {
+	c.url = url
+}
+
+// List prints the statuses of the commit named by ref, HEAD when ref is
+// empty.
+func (c *Checks) List(ref string) error {
+	query := fmt.Sprintf("%s.SerializeStatuses(%q)", c.config.RepositoryExpr(c.config.RealmPath), ref)
+	data, err := c.client.QueryString(query)
+	if err != nil {
+		return fmt.Errorf("failed to get statuses: %w", err)
+	}
+
+	count := 0
+	for _, line := range strings.Split(data, "\n") {
+		fields := gnokey.SplitEscaped(line, '|')
+		if len(fields) != 6 {
+			continue
+		}
+
+		fmt.Printf("%-8s %s", fields[1], fields[0])
+		if fields[2] != "" {
+			fmt.Printf(" - %s", fields[2])
+		}
+		if fields[3] != "" {
+			fmt.Printf(" (%s)", fields[3])
+		}
+		fmt.Println()
+		count++
+	}
+
+	if count == 0 {
+		fmt.Println("No checks reported")
+	}
+	return nil
+}
+
+// Set reports the state of the check context on commitHash.
+func (c *Checks) Set(commitHash, context, state string) error {
+	switch state {
+	case "pending", "success", "failure", "error":
+	default:
+		return fmt.Errorf("invalid state '%s' (expected pending, success, failure or error)", state)
+	}
+
+	arguments := fmt.Sprintf("%q, %q, %q, %q, %q", commitHash, context, state, c.description, c.url)
+	if err := c.call("SetStatus", arguments); err != nil {
+		return fmt.Errorf("failed to set status: %w", err)
+	}
+
+	fmt.Printf("Reported %s: %s\n", context, state)
+	return nil
+}
+
+// Allow lets reporter set statuses, or revokes it when allowed is false.
+func (c *Checks) Allow(reporter string, allowed bool) error {
+	method := "AddStatusReporter"
+	if !allowed {
+		method = "RemoveStatusReporter"
+	}
+
+	if err := c.call(method, fmt.Sprintf("address(%q)", reporter)); err != nil {
+		return fmt.Errorf("failed to update status reporters: %w", err)
+	}
+
+	if allowed {
+		fmt.Printf("%s can now report statuses\n", reporter)
+	} else {
+		fmt.Printf("%s can no longer report statuses\n", reporter)
+	}
+	return nil
+}
+
+// call runs a transaction calling method of the repository with arguments.
+func (c *Checks) call(method, arguments string) error {
+	gnoCode := fmt.Sprintf(`package main
+
+import %q
+
+func main() {
+	%s.%s(%s)
+}
+`, c.config.RealmPath, c.config.RepositoryExpr(config.PackageAlias(c.config.RealmPath)), method, arguments)
+
+	return c.client.Run(gnoCode)
+}
@@ -21,10 +21,18 @@ func NewClone(client *gnokey.Client, cfg *config.Config) *Clone {
 	}
 }
 
-func (c *Clone) Execute(realmPath string) error {
-	fmt.Printf("Cloning repository from '%s'...\n", realmPath)
-
-	repoName := extractRepoName(realmPath)
+// Execute clones the repository at address, either a realm path or
+// "<realm-path>#<name>" for a repository hosted in a Repositories container.
+// The latter is cloned into a directory called name, without the source of
+// the hosting realm.
+func (c *Clone) Execute(address string) error {
+	fmt.Printf("Cloning repository from '%s'...\n", address)
+
+	realmPath, name := config.SplitAddress(address)
+	repoName := name
+	if repoName == "" {
+		repoName = extractRepoName(realmPath)
+	}
 	if repoName == "" {
 		return fmt.Errorf("invalid realm path format")
 	}
@@ -44,18 +52,22 @@ func (c *Clone) Execute(realmPath string) error {
 	gnitFile := config.GnitFile{
 		StagedFiles: []string{},
 	}
+	if name != "" {
+		gnitFile.Realm = address
+	}
 
 	if err := WriteGnitFileData(&gnitFile); err != nil {
 		return fmt.Errorf("failed to create .gnit file: %w", err)
 	}
 
-	fmt.Printf("Initialized gnit repository with realm: %s\n", realmPath)
+	fmt.Printf("Initialized gnit repository with realm: %s\n", address)
 
 	cloneCfg := *c.config
 	cloneCfg.RealmPath = realmPath
+	cloneCfg.Repository = name
 
 	pull := NewPull(c.client, &cloneCfg)
-	pull.SetSourceMode(true)
+	pull.SetSourceMode(name == "")
 	if err := pull.ExecuteAll(); err != nil {
 		fmt.Printf("Warning: failed to pull files: %v\n", err)
 	}
@@ -2,7 +2,7 @@ package main
 
 import (
 	"fmt"
-	"os"
+	"strings"
 
 	config "github.com/gnoverse/gnit"
 	filesystem "github.com/gnoverse/gnit"
@@ -10,8 +10,9 @@ import (
 )
 
 type Commit struct {
-	client *gnokey.Client
-	config *config.Config
+	client   *gnokey.Client
+	config   *config.Config
+	trailers [][2]string
 }
 
 func NewCommit(client *gnokey.Client, cfg *config.Config) *Commit {
@@ -21,6 +22,34 @@ func NewCommit(client *gnokey.Client, cfg *config.Config) *Commit {
 	}
 }
 
+// AddCoAuthor credits coAuthor, usually "Name <email>", in a
+// Co-authored-by trailer.
+func (c *Commit) AddCoAuthor(coAuthor string) error {
+	return c.AddTrailer("Co-authored-by: " + coAuthor)
+}
+
+// AddTrailer appends a "Key: value" trailer to the commit message. Keys are
+// letters, digits and dashes, as the realm requires.
+func (c *Commit) AddTrailer(trailer string) error {
+	key, value, ok := strings.Cut(trailer, ":")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if !ok || !isTrailerKey(key) || value == "" || strings.ContainsAny(value, "\n\r") {
+		return fmt.Errorf("invalid trailer '%s' (expected 'Key: value', with a key of letters, digits and dashes)", trailer)
+	}
+	c.trailers = append(c.trailers, [2]string{key, value})
+	return nil
+}
+
+func isTrailerKey(key string) bool {
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return key != ""
+}
+
 func (c *Commit) Execute(message string) error {
 	if err := CheckGnitRepository(); err != nil {
 		return err
@@ -38,12 +67,18 @@ func (c *Commit) Execute(message string) error {
 	}
 
 	files := make(map[string][]byte)
+	modes := make(map[string]filesystem.FileMode)
 	for _, filename := range gnitFile.StagedFiles {
-		content, err := os.ReadFile(filename)
+		content, mode, err := filesystem.ReadEntry(filename)
 		if err != nil {
 			return fmt.Errorf("failed to read '%s': %w", filename, err)
 		}
 		files[filename] = content
+		modes[filename] = mode
+	}
+
+	for _, trailer := range c.trailers {
+		fmt.Printf("%s: %s\n", trailer[0], trailer[1])
 	}
 
 	fmt.Printf("Files to commit: %d\n", len(files))
@@ -51,7 +86,15 @@ func (c *Commit) Execute(message string) error {
 		fmt.Printf("  - %s\n", filename)
 	}
 
-	filesData := filesystem.SerializeFiles(files)
+	limits, err := c.client.Limits(c.config.Address())
+	if err != nil {
+		return err
+	}
+	if err := limits.Check(files); err != nil {
+		return fmt.Errorf("commit exceeds the repository limits: %w", err)
+	}
+
+	filesData := filesystem.SerializeFiles(files, modes)
 
 	gnoCode := c.generateCommitCode(message, filesData)
 
@@ -71,37 +114,30 @@ func (c *Commit) Execute(message string) error {
 func (c *Commit) generateCommitCode(message, filesData string) string {
 	packageAlias := config.PackageAlias(c.config.RealmPath)
 
+	call := fmt.Sprintf("CommitWithModes(%q, files, modes)", message)
+	if len(c.trailers) > 0 {
+		var trailers strings.Builder
+		for _, trailer := range c.trailers {
+			fmt.Fprintf(&trailers, "\n\t\t{Key: %q, Value: %q},", trailer[0], trailer[1])
+		}
+		call = fmt.Sprintf("CommitWithTrailers(%q, files, modes, []gnit.Trailer{%s\n\t})", message, trailers.String())
+	}
+
 	return fmt.Sprintf(`package main
 
 import (
+	"encoding/base64"
+	"strconv"
 	"strings"
+
+	%q
 	%q
 )
-
-func unescape(s string) string {
-	s = strings.ReplaceAll(s, "\\n", "\n")
-	s = strings.ReplaceAll(s, "\\|", "|")
-	s = strings.ReplaceAll(s, "\\\\", "\\")
-	return s
-}
-
+%s
 func main() {
-	filesData := %q
-	lines := strings.Split(filesData, "\n")
-	files := make(map[string][]byte)
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, "|", 2)
-		if len(parts) == 2 {
-			files[parts[0]] = []byte(unescape(parts[1]))
-		}
-	}
-
-	hash := %s.Repository.Commit(%q, files)
+	files, modes := decodeFiles(%q)
+	hash := %s.%s
 	println("Commit hash:", hash)
 }
-`, c.config.RealmPath, filesData, packageAlias, message)
+`, config.GnitPackagePath, c.config.RealmPath, gnokey.DecodeFilesFunc, filesData, c.config.RepositoryExpr(packageAlias), call)
 }
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	config "github.com/gnoverse/gnit"
+	gnokey "github.com/gnoverse/gnit"
+)
+
+type Export struct {
+	client  *gnokey.Client
+	config  *config.Config
+	address string
+}
+
+func NewExport(client *gnokey.Client, cfg *config.Config) *Export {
+	return &Export{
+		client:  client,
+		config:  cfg,
+		address: cfg.Address(),
+	}
+}
+
+// SetAddress selects the repository to export, as "<realm-path>" or
+// "<realm-path>#<name>".
+func (e *Export) SetAddress(address string) {
+	e.address = address
+}
+
+// Execute writes the history of the repository into a new git repository
+// at dir. With dir "-", the fast-import stream is written to stdout instead.
+func (e *Export) Execute(dir string) error {
+	if e.address == "" {
+		return fmt.Errorf("no realm path: run inside a gnit repository or pass --realm")
+	}
+
+	refs, err := e.client.ListRefs(e.address)
+	if err != nil {
+		return err
+	}
+	if len(refs) == 0 {
+		return fmt.Errorf("repository '%s' has no commits", e.address)
+	}
+
+	branch, err := e.client.CurrentBranch(e.address)
+	if err != nil {
+		return err
+	}
+	refs = headFirst(refs, branch)
+
+	if dir == "-" {
+		_, err := e.client.ExportFastImport(os.Stdout, e.address, refs, "refs/heads/")
+		return err
+	}
+
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("directory '%s' already exists and is not empty", dir)
+	}
+
+	fmt.Printf("Exporting '%s' to '%s'...\n", e.address, dir)
+
+	if err := runGit("", nil, "init", "-q", dir); err != nil {
+		return err
+	}
+
+	stream, writer := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := runGit(dir, stream, "fast-import", "--quiet")
+		stream.CloseWithError(fmt.Errorf("git fast-import stopped"))
+		done <- err
+	}()
+
+	count, err := e.client.ExportFastImport(writer, e.address, refs, "refs/heads/")
+	writer.CloseWithError(err)
+	if importErr := <-done; importErr != nil {
+		err = importErr
+	}
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+
+	if err := runGit(dir, nil, "symbolic-ref", "HEAD", "refs/heads/"+branch); err != nil {
+		return err
+	}
+	if err := runGit(dir, nil, "reset", "-q", "--hard"); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d commit(s) and %d branch(es) into '%s'\n", count, len(refs), dir)
+	return nil
+}
+
+// headFirst moves the branch HEAD points to to the front of refs.
+func headFirst(refs []gnokey.RemoteRef, branch string) []gnokey.RemoteRef {
+	for i, ref := range refs {
+		if ref.Name == branch {
+			ordered := append([]gnokey.RemoteRef{ref}, refs[:i]...)
+			return append(ordered, refs[i+1:]...)
+		}
+	}
+	return refs
+}
+
+func runGit(dir string, stdin io.Reader, args ...string) error {
+	name := args[0]
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = stdin
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	config "github.com/gnoverse/gnit"
+	filesystem "github.com/gnoverse/gnit"
+	gnokey "github.com/gnoverse/gnit"
+)
+
+type Import struct {
+	client *gnokey.Client
+	config *config.Config
+	ref    string
+}
+
+func NewImport(client *gnokey.Client, cfg *config.Config) *Import {
+	return &Import{
+		client: client,
+		config: cfg,
+		ref:    "HEAD",
+	}
+}
+
+func (i *Import) SetRef(ref string) {
+	i.ref = ref
+}
+
+func (i *Import) Execute(repoPath string) error {
+	if err := CheckGnitRepository(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Reading history of '%s' (%s)...\n", repoPath, i.ref)
+
+	cmd := exec.Command("git", "-C", repoPath, "fast-export",
+		"--reencode=yes",
+		"--signed-tags=strip",
+		"--tag-of-filtered-object=drop",
+		i.ref)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("git fast-export failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	stream, err := filesystem.ParseFastExport(bytes.NewReader(output))
+	if err != nil {
+		return fmt.Errorf("failed to parse git history: %w", err)
+	}
+
+	commits, err := stream.Replay()
+	if err != nil {
+		return fmt.Errorf("failed to replay git history: %w", err)
+	}
+
+	if len(commits) == 0 {
+		fmt.Println("No commits to import")
+		return nil
+	}
+
+	done, err := i.importedCommits(commits)
+	if err != nil {
+		return err
+	}
+	if done == len(commits) {
+		fmt.Printf("All %d commit(s) are already imported\n", done)
+		return nil
+	}
+	if done > 0 {
+		fmt.Printf("Resuming after %d commit(s) already imported\n", done)
+		commits = commits[done:]
+	}
+
+	limits, err := i.client.Limits(i.config.Address())
+	if err != nil {
+		return err
+	}
+	if err := limits.CheckReplay(commits); err != nil {
+		return fmt.Errorf("history exceeds the repository limits: %w", err)
+	}
+
+	batches := filesystem.BatchReplayCommits(commits)
+	fmt.Printf("Importing %d commit(s) in %d transaction(s)...\n", len(commits), len(batches))
+
+	imported := 0
+	for n, batch := range batches {
+		fmt.Printf("\nTransaction %d/%d: commits %d-%d\n", n+1, len(batches), imported+1, imported+len(batch))
+
+		gnoCode := gnokey.GenerateImportCode(i.config.Address(), batch)
+		if err := i.client.Run(gnoCode); err != nil {
+			return fmt.Errorf("import stopped after %d of %d commit(s), run it again to resume: %w", imported, len(commits), err)
+		}
+
+		imported += len(batch)
+	}
+
+	fmt.Printf("\nSuccessfully imported %d commit(s)\n", imported)
+	return nil
+}
+
+// importedCommits returns how many of commits, oldest first, are already
+// the history of the repository, as left by an interrupted import. Commits
+// match on message, author and timestamp. A repository with any other
+// history cannot be imported into.
+func (i *Import) importedCommits(commits []*filesystem.ReplayCommit) (int, error) {
+	existing, err := i.client.Log(i.config.Address(), "")
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) > len(commits) {
+		return 0, fmt.Errorf("repository already has %d commit(s); import needs an empty repository", len(existing))
+	}
+
+	for n := range existing {
+		remote := existing[len(existing)-1-n]
+		commit := commits[n]
+		if remote.Message != commit.Message || remote.AuthorName != commit.Author.Name ||
+			remote.AuthorEmail != commit.Author.Email || remote.Timestamp != commit.Author.When ||
+			len(remote.Parents) > 1 {
+			return 0, fmt.Errorf("repository already has %d commit(s) not from this history; import needs an empty repository", len(existing))
+		}
+	}
+	return len(existing), nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	config "github.com/gnoverse/gnit"
@@ -40,6 +41,24 @@ func main() {
 		handleCommit(client, cfg)
 	case "restore":
 		handleRestore(client, cfg)
+	case "search":
+		handleSearch(client, cfg)
+	case "import":
+		handleImport(client, cfg)
+	case "export":
+		handleExport(client, cfg)
+	case "verify":
+		handleVerify(client, cfg)
+	case "repo":
+		handleRepo(client, cfg)
+	case "review":
+		handleReview(client, cfg)
+	case "checks":
+		handleChecks(client, cfg)
+	case "show":
+		handleShow(client, cfg)
+	case "notes":
+		handleNotes(client, cfg)
 	case "help", "--help", "-h":
 		printUsage()
 	default:
@@ -61,7 +80,7 @@ func handleInit(client *gnokey.Client, cfg *config.Config) {
 func handleClone(client *gnokey.Client, cfg *config.Config) {
 	if len(os.Args) < 3 {
 		fmt.Println("Error: realm path required for clone")
-		fmt.Println("Usage: gnit clone <realm-path>")
+		fmt.Println("Usage: gnit clone <realm-path>[#<repository>]")
 		os.Exit(1)
 	}
 
@@ -153,17 +172,36 @@ func handleCommit(client *gnokey.Client, cfg *config.Config) {
 		os.Exit(1)
 	}
 
-	if len(os.Args) < 3 {
+	cmd := NewCommit(client, cfg)
+
+	var args []string
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		var err error
+		if arg == "--co-author" && i+1 < len(os.Args) {
+			err = cmd.AddCoAuthor(os.Args[i+1])
+			i++
+		} else if arg == "--trailer" && i+1 < len(os.Args) {
+			err = cmd.AddTrailer(os.Args[i+1])
+			i++
+		} else {
+			args = append(args, arg)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if len(args) == 0 {
 		fmt.Println("Error: message required for commit")
-		fmt.Println("Usage: gnit commit \"<message>\"")
+		fmt.Println("Usage: gnit commit [--co-author \"Name <email>\"]... [--trailer \"<key>: <value>\"]... \"<message>\"")
 		os.Exit(1)
 	}
 
-	message := strings.Join(os.Args[2:], " ")
+	message := strings.Join(args, " ")
 	message = strings.Trim(message, "\"")
 
-	cmd := NewCommit(client, cfg)
-
 	if err := cmd.Execute(message); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
@@ -200,18 +238,358 @@ func handleRestore(client *gnokey.Client, cfg *config.Config) {
 	}
 }
 
+func handleSearch(client *gnokey.Client, cfg *config.Config) {
+	if len(os.Args) < 3 {
+		fmt.Println("Error: search term required")
+		fmt.Println("Usage: gnit search <term>")
+		os.Exit(1)
+	}
+
+	term := strings.Join(os.Args[2:], " ")
+	cmd := NewSearch(client, cfg)
+
+	if err := cmd.Execute(term); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleImport(client *gnokey.Client, cfg *config.Config) {
+	if err := cfg.ValidateRealmPath(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cmd := NewImport(client, cfg)
+
+	var args []string
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		if (arg == "--ref" || arg == "-r") && i+1 < len(os.Args) {
+			cmd.SetRef(os.Args[i+1])
+			i++
+		} else {
+			args = append(args, arg)
+		}
+	}
+
+	if len(args) != 1 {
+		fmt.Println("Error: path to a git repository required for import")
+		fmt.Println("Usage: gnit import [--ref <ref>] <path-to-git-repo>")
+		os.Exit(1)
+	}
+
+	if err := cmd.Execute(args[0]); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleExport(client *gnokey.Client, cfg *config.Config) {
+	cmd := NewExport(client, cfg)
+
+	var args []string
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		if arg == "--realm" && i+1 < len(os.Args) {
+			cmd.SetAddress(os.Args[i+1])
+			i++
+		} else {
+			args = append(args, arg)
+		}
+	}
+
+	if len(args) != 1 {
+		fmt.Println("Error: target directory required for export")
+		fmt.Println("Usage: gnit export [--realm <address>] <directory|->")
+		os.Exit(1)
+	}
+
+	if err := cmd.Execute(args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleVerify(client *gnokey.Client, cfg *config.Config) {
+	if err := cfg.ValidateRealmPath(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cmd := NewVerify(client, cfg)
+
+	var paths []string
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		if (arg == "--commit" || arg == "-c") && i+1 < len(os.Args) {
+			cmd.SetCommit(os.Args[i+1])
+			i++
+		} else {
+			paths = append(paths, arg)
+		}
+	}
+
+	if len(paths) == 0 {
+		fmt.Println("Error: at least one file required for verify")
+		fmt.Println("Usage: gnit verify [--commit <hash>] <file>...")
+		os.Exit(1)
+	}
+
+	if err := cmd.Execute(paths); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleRepo(client *gnokey.Client, cfg *config.Config) {
+	if err := cfg.ValidateRealmPath(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cmd := NewRepo(client, cfg)
+
+	var err error
+	switch {
+	case len(os.Args) == 3 && os.Args[2] == "info":
+		err = cmd.Info()
+	case len(os.Args) == 3 && os.Args[2] == "limits":
+		err = cmd.Limits()
+	case len(os.Args) >= 4 && os.Args[2] == "set":
+		err = cmd.Set(os.Args[3], strings.Join(os.Args[4:], " "))
+	case len(os.Args) == 3 && (os.Args[2] == "archive" || os.Args[2] == "unarchive"):
+		err = cmd.Archive(os.Args[2] == "archive")
+	case len(os.Args) == 4 && os.Args[2] == "transfer":
+		err = cmd.Transfer(os.Args[3])
+	case len(os.Args) == 3 && os.Args[2] == "accept":
+		err = cmd.Accept()
+	case len(os.Args) == 5 && os.Args[2] == "collaborator" && (os.Args[3] == "add" || os.Args[3] == "remove"):
+		err = cmd.Collaborator(os.Args[4], os.Args[3] == "add")
+	default:
+		fmt.Println("Error: repo requires 'info', 'limits', 'set <field> <value>', 'archive', 'unarchive', 'transfer <address>', 'accept' or 'collaborator'")
+		fmt.Println("Usage: gnit repo info|limits")
+		fmt.Println("       gnit repo set <description|license|topics|homepage|branch> <value>")
+		fmt.Println("       gnit repo archive|unarchive")
+		fmt.Println("       gnit repo transfer <address>")
+		fmt.Println("       gnit repo accept")
+		fmt.Println("       gnit repo collaborator add|remove <address>")
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleReview(client *gnokey.Client, cfg *config.Config) {
+	if err := cfg.ValidateRealmPath(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cmd := NewReview(client, cfg)
+
+	file := ""
+	var args []string
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		if (arg == "--file" || arg == "-f") && i+1 < len(os.Args) {
+			file = os.Args[i+1]
+			i++
+		} else {
+			args = append(args, arg)
+		}
+	}
+
+	var err error
+	switch {
+	case len(args) == 0:
+		err = cmd.List("")
+	case args[0] == "list" && len(args) <= 2:
+		err = cmd.List(strings.Join(args[1:], ""))
+	case args[0] == "comment" && len(args) >= 3:
+		err = cmd.Comment(args[1], file, strings.Join(args[2:], " "))
+	case args[0] == "reply" && len(args) >= 3:
+		err = withThreadID(args[1], func(id int) error {
+			return cmd.Reply(id, strings.Join(args[2:], " "))
+		})
+	case (args[0] == "resolve" || args[0] == "reopen") && len(args) == 2:
+		err = withThreadID(args[1], func(id int) error {
+			return cmd.Resolve(id, args[0] == "resolve")
+		})
+	default:
+		fmt.Println("Error: invalid review command")
+		fmt.Println("Usage: gnit review [list [<commit>]]")
+		fmt.Println("       gnit review comment [--file <path>[:<line>]] <commit> <message>")
+		fmt.Println("       gnit review reply <thread> <message>")
+		fmt.Println("       gnit review resolve|reopen <thread>")
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleChecks(client *gnokey.Client, cfg *config.Config) {
+	if err := cfg.ValidateRealmPath(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cmd := NewChecks(client, cfg)
+
+	var args []string
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		if (arg == "--description" || arg == "-d") && i+1 < len(os.Args) {
+			cmd.SetDescription(os.Args[i+1])
+			i++
+		} else if arg == "--url" && i+1 < len(os.Args) {
+			cmd.SetURL(os.Args[i+1])
+			i++
+		} else {
+			args = append(args, arg)
+		}
+	}
+
+	var err error
+	switch {
+	case len(args) == 0:
+		err = cmd.List("")
+	case args[0] == "list" && len(args) <= 2:
+		err = cmd.List(strings.Join(args[1:], ""))
+	case args[0] == "set" && len(args) == 4:
+		err = cmd.Set(args[1], args[2], args[3])
+	case (args[0] == "allow" || args[0] == "revoke") && len(args) == 2:
+		err = cmd.Allow(args[1], args[0] == "allow")
+	default:
+		fmt.Println("Error: invalid checks command")
+		fmt.Println("Usage: gnit checks [list [<commit>]]")
+		fmt.Println("       gnit checks set [--description <text>] [--url <url>] <commit> <context> <pending|success|failure|error>")
+		fmt.Println("       gnit checks allow|revoke <address>")
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleShow(client *gnokey.Client, cfg *config.Config) {
+	if err := cfg.ValidateRealmPath(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(os.Args) > 3 {
+		fmt.Println("Usage: gnit show [<commit>]")
+		os.Exit(1)
+	}
+
+	cmd := NewShow(client, cfg)
+
+	if err := cmd.Execute(strings.Join(os.Args[2:], "")); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleNotes(client *gnokey.Client, cfg *config.Config) {
+	if err := cfg.ValidateRealmPath(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cmd := NewNotes(client, cfg)
+
+	namespace := ""
+	var args []string
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		if (arg == "--namespace" || arg == "-n") && i+1 < len(os.Args) {
+			namespace = os.Args[i+1]
+			i++
+		} else {
+			args = append(args, arg)
+		}
+	}
+
+	var err error
+	switch {
+	case len(args) == 0:
+		err = cmd.List("", namespace)
+	case args[0] == "list" && len(args) <= 2:
+		err = cmd.List(strings.Join(args[1:], ""), namespace)
+	case args[0] == "add" && len(args) >= 4:
+		err = cmd.Add(args[1], args[2], strings.Join(args[3:], " "))
+	default:
+		fmt.Println("Error: invalid notes command")
+		fmt.Println("Usage: gnit notes [list [<commit>]] [--namespace <namespace>]")
+		fmt.Println("       gnit notes add <commit> <namespace> <text>")
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func withThreadID(arg string, fn func(id int) error) error {
+	id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil {
+		return fmt.Errorf("invalid thread '%s'", arg)
+	}
+	return fn(id)
+}
+
 func printUsage() {
 	fmt.Println("Usage: gnit <command> [options]")
 	fmt.Println()
 	fmt.Println("Available commands:")
 	fmt.Println("  clone <realm-path>       Clone a repository from a realm path")
+	fmt.Println("    <realm-path>#<name>    Clone repository <name> of a realm hosting several")
 	fmt.Println("  add <file|directory>...  Stage files or directories for commit")
 	fmt.Println("  status                   Show the working tree status")
 	fmt.Println("  pull [options] [file]    Fetch file(s) from the repository")
 	fmt.Println("    --source, -s           Also pull the realm source code to realm.gno")
 	fmt.Println("  commit <message>         Commit staged changes with a message")
+	fmt.Println("    --co-author <name <email>> Credit a co-author (repeatable)")
+	fmt.Println("    --trailer <key: value> Add a trailer such as \"Fixes: #12\" (repeatable)")
 	fmt.Println("  restore [options] [file] Restore working tree files or unstage files")
 	fmt.Println("    --staged, -s           Restore files in the staging area (unstage)")
+	fmt.Println("  search <term>            Search the registry for repositories")
+	fmt.Println("  import [options] <path>  Replay the history of a git repository")
+	fmt.Println("    --ref, -r <ref>        Branch or commit to import (default: HEAD)")
+	fmt.Println("  export [options] <dir>   Write the repository history as a git repository")
+	fmt.Println("    --realm <address>      Repository to export (default: current repository)")
+	fmt.Println("  verify [options] <file>... Check local files against a commit's inclusion proof")
+	fmt.Println("    --commit, -c <hash>    Commit to verify against (default: HEAD)")
+	fmt.Println("  repo info                Show the repository metadata")
+	fmt.Println("  repo limits              Show the size and path limits enforced on commits")
+	fmt.Println("  repo set <field> <value> Set description, license, topics, homepage or branch")
+	fmt.Println("  repo archive|unarchive   Make the repository read-only, or writable again")
+	fmt.Println("  repo transfer <address>  Propose a new owner, who runs 'repo accept'")
+	fmt.Println("  review [list [<commit>]] List the review threads of a commit (default: HEAD)")
+	fmt.Println("  review comment <commit> <message> Comment on a commit")
+	fmt.Println("    --file, -f <path>[:<line>] Comment on a file or one of its lines")
+	fmt.Println("  review reply <thread> <message> Reply to a review thread")
+	fmt.Println("  review resolve|reopen <thread> Resolve or reopen a review thread")
+	fmt.Println("  checks [list [<commit>]] List the status checks of a commit (default: HEAD)")
+	fmt.Println("  checks set <commit> <context> <state> Report a check (status reporters only)")
+	fmt.Println("    --description, -d <text> Short description of the result")
+	fmt.Println("    --url <url>            Link to the full result")
+	fmt.Println("  checks allow|revoke <address> Manage status reporters (owner only)")
+	fmt.Println("  show [<commit>]          Show a commit and its notes (default: HEAD)")
+	fmt.Println("  notes [list [<commit>]]  List the notes of a commit (default: HEAD)")
+	fmt.Println("    --namespace, -n <name> Only list notes of one namespace")
+	fmt.Println("  notes add <commit> <namespace> <text> Attach a note to a commit (collaborators only)")
+	fmt.Println("  repo collaborator add|remove <address> Manage collaborators (owner only)")
 	fmt.Println("  help                     Display this help")
 	fmt.Println()
 	fmt.Println("Examples:")
@@ -225,4 +603,13 @@ func printUsage() {
 	fmt.Println("  gnit restore file.gno        # Restore file from repository")
 	fmt.Println("  gnit restore --staged file.gno # Unstage file")
 	fmt.Println("  gnit restore --staged        # Unstage all files")
+	fmt.Println("  gnit search helpers          # Find repositories to clone")
+	fmt.Println("  gnit import ../myrepo        # Import git history into the realm")
+	fmt.Println("  gnit export ../myrepo-git    # Export the realm history to git")
+	fmt.Println("  gnit export - > repo.fi      # Write a git fast-import stream")
+	fmt.Println("  gnit verify -c 1a2b3c main.gno # Check main.gno was committed at 1a2b3c")
+	fmt.Println("  gnit repo set topics gno,tools # Set comma-separated topics")
+	fmt.Println("  gnit review comment -f main.gno:12 1a2b3c \"Handle the error\"")
+	fmt.Println("  gnit checks set 1a2b3c gno-test success -d \"42 tests passed\"")
+	fmt.Println("  gnit notes add 1a2b3c deploy \"tx 9F2C..., gas 1200000\"")
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	config "github.com/gnoverse/gnit"
+	gnokey "github.com/gnoverse/gnit"
+)
+
+type Notes struct {
+	client *gnokey.Client
+	config *config.Config
+}
+
+func NewNotes(client *gnokey.Client, cfg *config.Config) *Notes {
+	return &Notes{
+		client: client,
+		config: cfg,
+	}
+}
+
+// List prints the notes of the commit named by ref, HEAD when ref is empty,
+// in namespace or in every namespace when namespace is empty.
+func (n *Notes) List(ref, namespace string) error {
+	notes, err := n.client.Notes(n.config.Address(), ref, namespace)
+	if err != nil {
+		return err
+	}
+
+	if len(notes) == 0 {
+		fmt.Println("No notes")
+		return nil
+	}
+	printNotes(notes)
+	return nil
+}
+
+// Add attaches text to commitHash under namespace.
+func (n *Notes) Add(commitHash, namespace, text string) error {
+	arguments := fmt.Sprintf("%q, %q, %q", commitHash, namespace, text)
+	if err := n.call("AddNote", arguments); err != nil {
+		return fmt.Errorf("failed to add note: %w", err)
+	}
+
+	fmt.Printf("Added %s note to %s\n", namespace, commitHash)
+	return nil
+}
+
+// call runs a transaction calling method of the repository with arguments.
+func (n *Notes) call(method, arguments string) error {
+	gnoCode := fmt.Sprintf(`package main
+
+import %q
+
+func main() {
+	%s.%s(%s)
+}
+`, n.config.RealmPath, n.config.RepositoryExpr(config.PackageAlias(n.config.RealmPath)), method, arguments)
+
+	return n.client.Run(gnoCode)
+}
@@ -34,19 +34,20 @@ func (p *Pull) Execute(filename string) error {
 
 	fmt.Printf("Pulling '%s'...\n", filename)
 
-	packageAlias := config.PackageAlias(p.config.RealmPath)
-	query := fmt.Sprintf("%s.Repository.Pull(\"%s\")", packageAlias, filename)
-
-	content, err := p.client.RunQuery(p.config.RealmPath, query)
+	_, files, _, err := p.client.GetFiles(p.config.Address(), "", []string{filename})
 	if err != nil {
 		return fmt.Errorf("failed to query file: %w", err)
 	}
+	if len(files) == 0 {
+		return fmt.Errorf("file '%s' not found in repository", filename)
+	}
 
-	if err := filesystem.WriteFile(filename, content); err != nil {
+	file := files[0]
+	if err := filesystem.WriteFileMode(file.Path, file.Content, file.Mode); err != nil {
 		return err
 	}
 
-	fmt.Printf("File '%s' fetched successfully (%d bytes)\n", filename, len(content))
+	fmt.Printf("File '%s' fetched successfully (%d bytes)\n", filename, len(file.Content))
 	return nil
 }
 
@@ -57,39 +58,13 @@ func (p *Pull) ExecuteAll() error {
 
 	fmt.Println("Pulling all files from repository...")
 
-	packageAlias := config.PackageAlias(p.config.RealmPath)
-
-	listQuery := fmt.Sprintf("%s.Repository.ListFiles()", packageAlias)
-	listData, err := p.client.RunQuery(p.config.RealmPath, listQuery)
+	_, files, err := p.client.ExportFiles(p.config.Address(), "")
 	if err != nil {
 		if p.sourceMode {
 			fmt.Println("Repository not found or empty, trying to pull realm source files...")
 			return p.pullRealmSource()
 		}
-		return fmt.Errorf("failed to list files: %w", err)
-	}
-
-	filenames, err := parseFileList(string(listData))
-	if err != nil {
-		return fmt.Errorf("failed to parse file list: %w", err)
-	}
-
-	if len(filenames) == 0 {
-		fmt.Println("No files found in repository")
-		if p.sourceMode {
-			return p.pullRealmSource()
-		}
-		return nil
-	}
-
-	files := make(map[string][]byte)
-	for _, filename := range filenames {
-		pullQuery := fmt.Sprintf("%s.Repository.Pull(\"%s\")", packageAlias, filename)
-		content, err := p.client.RunQuery(p.config.RealmPath, pullQuery)
-		if err != nil {
-			return fmt.Errorf("failed to pull file %s: %w", filename, err)
-		}
-		files[filename] = content
+		return fmt.Errorf("failed to fetch files: %w", err)
 	}
 
 	if len(files) == 0 {
@@ -102,11 +77,11 @@ func (p *Pull) ExecuteAll() error {
 
 	fmt.Printf("Found %d file(s), writing to disk...\n", len(files))
 
-	for filename, content := range files {
-		if err := filesystem.WriteFile(filename, content); err != nil {
-			return fmt.Errorf("failed to write '%s': %w", filename, err)
+	for _, file := range files {
+		if err := filesystem.WriteFileMode(file.Path, file.Content, file.Mode); err != nil {
+			return fmt.Errorf("failed to write '%s': %w", file.Path, err)
 		}
-		fmt.Printf("  pulled: %s (%d bytes)\n", filename, len(content))
+		fmt.Printf("  pulled: %s (%d bytes)\n", file.Path, len(file.Content))
 	}
 
 	fmt.Printf("\nSuccessfully pulled %d file(s)\n", len(files))
@@ -181,157 +156,3 @@ func parseRealmFileList(output string) []string {
 
 	return files
 }
-
-func parseSerializedFiles(data string) (map[string][]byte, error) {
-	data = strings.TrimSpace(data)
-	if strings.HasPrefix(data, "data: (slice[") {
-		start := strings.Index(data, "slice[")
-		if start == -1 {
-			return make(map[string][]byte), fmt.Errorf("invalid format: no 'slice[' found")
-		}
-		start += len("slice[")
-
-		end := strings.LastIndex(data, "]")
-		if end == -1 || end <= start {
-			return make(map[string][]byte), fmt.Errorf("invalid format: no closing ']' found")
-		}
-
-		hexData := data[start:end]
-
-		var decoded []byte
-		if strings.HasPrefix(hexData, "0x") {
-			hexData = hexData[2:]
-		}
-
-		for i := 0; i < len(hexData); i += 2 {
-			if i+1 >= len(hexData) {
-				break
-			}
-			var b byte
-			fmt.Sscanf(hexData[i:i+2], "%02x", &b)
-			decoded = append(decoded, b)
-		}
-
-		data = string(decoded)
-	}
-
-	lines := strings.Split(data, "\n")
-	files := make(map[string][]byte)
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-
-		parts := strings.SplitN(line, "|", 2)
-		if len(parts) != 2 {
-			continue
-		}
-
-		filename := unescapeString(parts[0])
-		content := unescapeString(parts[1])
-		files[filename] = []byte(content)
-	}
-
-	return files, nil
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-func unescapeString(s string) string {
-	var result strings.Builder
-	i := 0
-	for i < len(s) {
-		if s[i] == '\\' && i+1 < len(s) {
-			next := s[i+1]
-			switch next {
-			case '\\':
-				result.WriteByte('\\')
-				i += 2
-			case '|':
-				result.WriteByte('|')
-				i += 2
-			case 'n':
-				result.WriteByte('\n')
-				i += 2
-			case 'r':
-				result.WriteByte('\r')
-				i += 2
-			case 't':
-				result.WriteByte('\t')
-				i += 2
-			default:
-				result.WriteByte(s[i])
-				i++
-			}
-		} else {
-			result.WriteByte(s[i])
-			i++
-		}
-	}
-	return result.String()
-}
-
-func parseFileList(data string) ([]string, error) {
-	str := strings.TrimSpace(data)
-
-	str = strings.TrimPrefix(str, "data: ")
-
-	sliceStart := strings.Index(str, "slice[")
-	if sliceStart == -1 {
-		return []string{}, nil
-	}
-
-	sliceStart += len("slice[")
-	sliceEnd := strings.LastIndex(str, "]")
-	if sliceEnd == -1 || sliceEnd <= sliceStart {
-		return []string{}, nil
-	}
-
-	content := str[sliceStart:sliceEnd]
-	if strings.TrimSpace(content) == "" {
-		return []string{}, nil
-	}
-
-	var files []string
-	var currentFile strings.Builder
-	inQuotes := false
-	escaped := false
-
-	for i := 0; i < len(content); i++ {
-		ch := content[i]
-
-		if escaped {
-			currentFile.WriteByte(ch)
-			escaped = false
-			continue
-		}
-
-		if ch == '\\' {
-			escaped = true
-			continue
-		}
-
-		if ch == '"' {
-			if inQuotes {
-				files = append(files, currentFile.String())
-				currentFile.Reset()
-				inQuotes = false
-			} else {
-				inQuotes = true
-			}
-			continue
-		}
-
-		if inQuotes {
-			currentFile.WriteByte(ch)
-		}
-	}
-
-	return files, nil
-}
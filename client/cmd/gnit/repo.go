@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	config "github.com/gnoverse/gnit"
+	gnokey "github.com/gnoverse/gnit"
+)
+
+// repoSetters maps the fields of 'gnit repo set' to Repository setters.
+var repoSetters = map[string]string{
+	"description": "SetDescription",
+	"license":     "SetLicense",
+	"topics":      "SetTopics",
+	"homepage":    "SetHomepage",
+	"branch":      "SetDefaultBranch",
+}
+
+type Repo struct {
+	client *gnokey.Client
+	config *config.Config
+}
+
+func NewRepo(client *gnokey.Client, cfg *config.Config) *Repo {
+	return &Repo{
+		client: client,
+		config: cfg,
+	}
+}
+
+func (r *Repo) Info() error {
+	query := fmt.Sprintf("%s.SerializeMetadata()", r.config.RepositoryExpr(r.config.RealmPath))
+	data, err := r.client.QueryString(query)
+	if err != nil {
+		return fmt.Errorf("failed to get repository metadata: %w", err)
+	}
+
+	labels := map[string]string{
+		"name":          "Name",
+		"owner":         "Owner",
+		"description":   "Description",
+		"license":       "License",
+		"topics":        "Topics",
+		"homepage":      "Homepage",
+		"branch":        "Default branch",
+		"archived":      "Archived",
+		"pending_owner": "Pending owner",
+	}
+
+	for _, line := range strings.Split(data, "\n") {
+		fields := gnokey.SplitEscaped(line, '|')
+		if len(fields) != 2 || fields[1] == "" || fields[1] == "false" {
+			continue
+		}
+
+		label, ok := labels[fields[0]]
+		if !ok {
+			continue
+		}
+		fmt.Printf("%-15s %s\n", label+":", fields[1])
+	}
+
+	return nil
+}
+
+// Limits prints the limits the realm enforces on commits.
+func (r *Repo) Limits() error {
+	limits, err := r.client.Limits(r.config.Address())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-20s %s\n", "Max file size:", formatLimit(limits.MaxBlobSize, " bytes"))
+	fmt.Printf("%-20s %s\n", "Max files/commit:", formatLimit(limits.MaxFilesPerCommit, ""))
+	fmt.Printf("%-20s %s\n", "Max repository size:", formatLimit(limits.MaxRepositoryBytes, " bytes"))
+	fmt.Printf("%-20s %d bytes\n", "Stored:", limits.StoredBytes)
+	if len(limits.AllowedPaths) > 0 {
+		fmt.Printf("%-20s %s\n", "Allowed paths:", strings.Join(limits.AllowedPaths, " "))
+	}
+	return nil
+}
+
+func formatLimit(limit int, unit string) string {
+	if limit == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d%s", limit, unit)
+}
+
+func (r *Repo) Set(field, value string) error {
+	setter, ok := repoSetters[field]
+	if !ok {
+		return fmt.Errorf("unknown field '%s' (expected description, license, topics, homepage or branch)", field)
+	}
+
+	argument := fmt.Sprintf("%q", value)
+	if field == "topics" {
+		var topics []string
+		for _, topic := range strings.Split(value, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				topics = append(topics, fmt.Sprintf("%q", topic))
+			}
+		}
+		argument = "[]string{" + strings.Join(topics, ", ") + "}"
+	}
+
+	fmt.Printf("Setting %s of %s...\n", field, r.config.Address())
+
+	if err := r.call(setter, argument); err != nil {
+		return fmt.Errorf("failed to set %s: %w", field, err)
+	}
+
+	fmt.Printf("Updated %s\n", field)
+	return nil
+}
+
+// Archive makes the repository read-only, or writable again when archived
+// is false.
+func (r *Repo) Archive(archived bool) error {
+	method, done := "Archive", "Archived"
+	if !archived {
+		method, done = "Unarchive", "Unarchived"
+	}
+
+	if err := r.call(method, ""); err != nil {
+		return fmt.Errorf("failed to %s repository: %w", strings.ToLower(method), err)
+	}
+
+	fmt.Printf("%s %s\n", done, r.config.Address())
+	return nil
+}
+
+// Transfer proposes newOwner as the owner of the repository. The transfer
+// completes when newOwner runs Accept.
+func (r *Repo) Transfer(newOwner string) error {
+	if err := r.call("TransferOwnership", fmt.Sprintf("address(%q)", newOwner)); err != nil {
+		return fmt.Errorf("failed to propose ownership transfer: %w", err)
+	}
+
+	fmt.Printf("Proposed %s as owner of %s; they must run 'gnit repo accept'\n", newOwner, r.config.Address())
+	return nil
+}
+
+func (r *Repo) Accept() error {
+	if err := r.call("AcceptOwnership", ""); err != nil {
+		return fmt.Errorf("failed to accept ownership: %w", err)
+	}
+
+	fmt.Printf("You now own %s\n", r.config.Address())
+	return nil
+}
+
+// Collaborator gives collaborator access to addr, or revokes it when
+// allowed is false.
+func (r *Repo) Collaborator(addr string, allowed bool) error {
+	method := "AddCollaborator"
+	if !allowed {
+		method = "RemoveCollaborator"
+	}
+
+	if err := r.call(method, fmt.Sprintf("address(%q)", addr)); err != nil {
+		return fmt.Errorf("failed to update collaborators: %w", err)
+	}
+
+	if allowed {
+		fmt.Printf("%s is now a collaborator\n", addr)
+	} else {
+		fmt.Printf("%s is no longer a collaborator\n", addr)
+	}
+	return nil
+}
+
+// call runs a transaction calling method of the repository with argument.
+func (r *Repo) call(method, argument string) error {
+	gnoCode := fmt.Sprintf(`package main
+
+import %q
+
+func main() {
+	%s.%s(%s)
+}
+`, r.config.RealmPath, r.config.RepositoryExpr(config.PackageAlias(r.config.RealmPath)), method, argument)
+
+	return r.client.Run(gnoCode)
+}
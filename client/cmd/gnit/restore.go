@@ -101,23 +101,12 @@ func (r *Restore) restoreStaged(paths []string) error {
 }
 
 func (r *Restore) restoreWorkingTree(paths []string) error {
-	packageAlias := config.PackageAlias(r.config.RealmPath)
-	query := fmt.Sprintf("%s.Repository.SerializePullAll()", packageAlias)
-
-	serializedData, err := r.client.RunQuery(r.config.RealmPath, query)
-	if err != nil {
-		return fmt.Errorf("failed to fetch repository files: %w", err)
-	}
-
-	committedFiles := make(map[string][]byte)
-	if len(serializedData) > 0 {
-		committedFiles, err = parseSerializedFiles(string(serializedData))
+	if len(paths) == 0 {
+		_, committedFiles, err := r.client.ExportFiles(r.config.Address(), "")
 		if err != nil {
-			return fmt.Errorf("failed to parse repository files: %w", err)
+			return fmt.Errorf("failed to fetch repository files: %w", err)
 		}
-	}
 
-	if len(paths) == 0 {
 		if len(committedFiles) == 0 {
 			fmt.Println("No files found in repository to restore")
 			return nil
@@ -125,38 +114,39 @@ func (r *Restore) restoreWorkingTree(paths []string) error {
 
 		fmt.Printf("Restoring %d file(s) from repository...\n", len(committedFiles))
 
-		for filename, content := range committedFiles {
-			if err := filesystem.WriteFile(filename, content); err != nil {
-				return fmt.Errorf("failed to restore '%s': %w", filename, err)
+		for _, file := range committedFiles {
+			if err := filesystem.WriteFileMode(file.Path, file.Content, file.Mode); err != nil {
+				return fmt.Errorf("failed to restore '%s': %w", file.Path, err)
 			}
-			fmt.Printf("  restored: %s (%d bytes)\n", filename, len(content))
+			fmt.Printf("  restored: %s (%d bytes)\n", file.Path, len(file.Content))
 		}
 
 		fmt.Printf("\nSuccessfully restored %d file(s)\n", len(committedFiles))
 		return nil
 	}
 
-	restoredCount := 0
-	for _, path := range paths {
-		content, exists := committedFiles[path]
-		if !exists {
-			fmt.Printf("Warning: '%s' not found in repository\n", path)
-			continue
-		}
+	_, files, missing, err := r.client.GetFiles(r.config.Address(), "", paths)
+	if err != nil {
+		return fmt.Errorf("failed to fetch repository files: %w", err)
+	}
+
+	for _, path := range missing {
+		fmt.Printf("Warning: '%s' not found in repository\n", path)
+	}
 
-		if err := filesystem.WriteFile(path, content); err != nil {
-			return fmt.Errorf("failed to restore '%s': %w", path, err)
+	for _, file := range files {
+		if err := filesystem.WriteFileMode(file.Path, file.Content, file.Mode); err != nil {
+			return fmt.Errorf("failed to restore '%s': %w", file.Path, err)
 		}
 
-		fmt.Printf("  restored: %s (%d bytes)\n", path, len(content))
-		restoredCount++
+		fmt.Printf("  restored: %s (%d bytes)\n", file.Path, len(file.Content))
 	}
 
-	if restoredCount == 0 {
+	if len(files) == 0 {
 		fmt.Println("No files were restored")
 		return nil
 	}
 
-	fmt.Printf("\nSuccessfully restored %d file(s)\n", restoredCount)
+	fmt.Printf("\nSuccessfully restored %d file(s)\n", len(files))
 	return nil
 }
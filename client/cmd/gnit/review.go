@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	config "github.com/gnoverse/gnit"
+	gnokey "github.com/gnoverse/gnit"
+)
+
+type Review struct {
+	client *gnokey.Client
+	config *config.Config
+}
+
+func NewReview(client *gnokey.Client, cfg *config.Config) *Review {
+	return &Review{
+		client: client,
+		config: cfg,
+	}
+}
+
+// List prints the review threads of the commit named by ref, HEAD when ref
+// is empty.
+func (r *Review) List(ref string) error {
+	query := fmt.Sprintf("%s.SerializeThreads(%q)", r.config.RepositoryExpr(r.config.RealmPath), ref)
+	data, err := r.client.QueryString(query)
+	if err != nil {
+		return fmt.Errorf("failed to get review threads: %w", err)
+	}
+
+	count := 0
+	for _, line := range strings.Split(data, "\n") {
+		fields := gnokey.SplitEscaped(line, '|')
+		switch {
+		case fields[0] == "thread" && len(fields) == 5:
+			location := fields[2]
+			if location == "" {
+				location = "(commit)"
+			} else if fields[3] != "0" {
+				location += ":" + fields[3]
+			}
+
+			if count > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("#%s %s", fields[1], location)
+			if fields[4] == "true" {
+				fmt.Print(" (resolved)")
+			}
+			fmt.Println()
+			count++
+		case fields[0] == "comment" && len(fields) == 5:
+			timestamp, _ := strconv.ParseInt(fields[3], 10, 64)
+			date := time.Unix(timestamp, 0).UTC().Format("2006-01-02 15:04")
+			fmt.Printf("    %s on %s:\n", fields[2], date)
+			for _, bodyLine := range strings.Split(fields[4], "\n") {
+				fmt.Printf("        %s\n", bodyLine)
+			}
+		}
+	}
+
+	if count == 0 {
+		fmt.Println("No review comments")
+	}
+	return nil
+}
+
+// Comment starts a thread on commitHash. location is empty for the whole
+// commit, or "<path>" or "<path>:<line>".
+func (r *Review) Comment(commitHash, location, body string) error {
+	path, line := location, 0
+	if i := strings.LastIndex(location, ":"); i >= 0 {
+		n, err := strconv.Atoi(location[i+1:])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid line in '%s'", location)
+		}
+		path, line = location[:i], n
+	}
+
+	arguments := fmt.Sprintf("%q, %q, %d, %q", commitHash, path, line, body)
+	if err := r.call("AddComment", arguments); err != nil {
+		return fmt.Errorf("failed to post comment: %w", err)
+	}
+
+	fmt.Println("Comment posted")
+	return nil
+}
+
+func (r *Review) Reply(threadID int, body string) error {
+	if err := r.call("Reply", fmt.Sprintf("%d, %q", threadID, body)); err != nil {
+		return fmt.Errorf("failed to reply to #%d: %w", threadID, err)
+	}
+
+	fmt.Printf("Replied to #%d\n", threadID)
+	return nil
+}
+
+// Resolve marks a thread resolved, or open again when resolved is false.
+func (r *Review) Resolve(threadID int, resolved bool) error {
+	if err := r.call("ResolveThread", fmt.Sprintf("%d, %t", threadID, resolved)); err != nil {
+		return fmt.Errorf("failed to update #%d: %w", threadID, err)
+	}
+
+	if resolved {
+		fmt.Printf("Resolved #%d\n", threadID)
+	} else {
+		fmt.Printf("Reopened #%d\n", threadID)
+	}
+	return nil
+}
+
+// call runs a transaction calling method of the repository with arguments.
+func (r *Review) call(method, arguments string) error {
+	gnoCode := fmt.Sprintf(`package main
+
+import %q
+
+func main() {
+	%s.%s(%s)
+}
+`, r.config.RealmPath, r.config.RepositoryExpr(config.PackageAlias(r.config.RealmPath)), method, arguments)
+
+	return r.client.Run(gnoCode)
+}
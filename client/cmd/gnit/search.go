@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	config "github.com/gnoverse/gnit"
+	gnokey "github.com/gnoverse/gnit"
+)
+
+const searchLimit = 50
+
+type Search struct {
+	client *gnokey.Client
+	config *config.Config
+}
+
+func NewSearch(client *gnokey.Client, cfg *config.Config) *Search {
+	return &Search{
+		client: client,
+		config: cfg,
+	}
+}
+
+func (s *Search) Execute(term string) error {
+	query := fmt.Sprintf("%s.SearchSerialized(%q, %d)", s.config.RegistryPath, term, searchLimit)
+
+	data, err := s.client.QueryString(query)
+	if err != nil {
+		return fmt.Errorf("failed to search registry: %w", err)
+	}
+
+	var found int
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := gnokey.SplitEscaped(line, '|')
+		if len(fields) != 3 {
+			continue
+		}
+
+		found++
+		fmt.Printf("%s (%s)\n", fields[0], fields[1])
+		if fields[2] != "" {
+			fmt.Printf("    %s\n", fields[2])
+		}
+	}
+
+	if found == 0 {
+		fmt.Printf("No repositories matching '%s'\n", term)
+		return nil
+	}
+
+	fmt.Printf("\n%d repository(ies) found. Clone one with 'gnit clone <realm-path>'\n", found)
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	config "github.com/gnoverse/gnit"
+	gnokey "github.com/gnoverse/gnit"
+)
+
+type Show struct {
+	client *gnokey.Client
+	config *config.Config
+}
+
+func NewShow(client *gnokey.Client, cfg *config.Config) *Show {
+	return &Show{
+		client: client,
+		config: cfg,
+	}
+}
+
+// Execute prints the commit named by ref, HEAD when ref is empty, with its
+// notes.
+func (s *Show) Execute(ref string) error {
+	commits, err := s.client.LogPage(s.config.Address(), ref, 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("unknown commit '%s'", ref)
+	}
+	commit := commits[0]
+
+	notes, err := s.client.Notes(s.config.Address(), commit.Hash, "")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("commit %s\n", commit.Hash)
+	if len(commit.Parents) > 1 {
+		fmt.Printf("Merge: %s\n", strings.Join(commit.Parents, " "))
+	}
+	author := commit.AuthorName
+	if author == "" {
+		author = commit.AuthorAddress
+	}
+	if commit.AuthorEmail != "" {
+		author += " <" + commit.AuthorEmail + ">"
+	}
+	fmt.Printf("Author: %s\n", author)
+	fmt.Printf("Date:   %s\n", formatDate(commit.Timestamp))
+	fmt.Println()
+	for _, line := range strings.Split(commit.Message, "\n") {
+		fmt.Printf("    %s\n", line)
+	}
+
+	printNotes(notes)
+	return nil
+}
+
+// printNotes prints notes like git log: a "Notes (namespace):" header per
+// namespace, then the indented text of each note.
+func printNotes(notes []gnokey.RemoteNote) {
+	for i, note := range notes {
+		if i == 0 || notes[i-1].Namespace != note.Namespace {
+			fmt.Printf("\nNotes (%s):\n", note.Namespace)
+		}
+		for _, line := range strings.Split(note.Text, "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+		fmt.Printf("    -- %s, %s\n", note.Author, formatDate(note.Timestamp))
+	}
+}
+
+func formatDate(timestamp int64) string {
+	return time.Unix(timestamp, 0).UTC().Format("2006-01-02 15:04 UTC")
+}
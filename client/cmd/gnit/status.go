@@ -30,33 +30,14 @@ func (s *Status) Execute() error {
 		return fmt.Errorf("failed to read .gnit file: %w", err)
 	}
 
-	packageAlias := config.PackageAlias(s.config.RealmPath)
-
-	listQuery := fmt.Sprintf("%s.Repository.ListFiles()", packageAlias)
-	listData, err := s.client.RunQuery(s.config.RealmPath, listQuery)
+	localFiles, err := getLocalFiles()
 	if err != nil {
-		listData = []byte{}
-	}
-
-	committedFiles := make(map[string][]byte)
-
-	if len(listData) > 0 {
-		filenames, err := parseFileList(string(listData))
-		if err == nil {
-			for _, filename := range filenames {
-				pullQuery := fmt.Sprintf("%s.Repository.Pull(\"%s\")", packageAlias, filename)
-				content, err := s.client.RunQuery(s.config.RealmPath, pullQuery)
-				if err != nil {
-					continue
-				}
-				committedFiles[filename] = content
-			}
-		}
+		return fmt.Errorf("failed to get local files: %w", err)
 	}
 
-	localFiles, err := getLocalFiles()
+	committedFiles, err := s.fetchCommittedFiles(localFiles)
 	if err != nil {
-		return fmt.Errorf("failed to get local files: %w", err)
+		committedFiles = make(map[string][]byte)
 	}
 
 	stagedFiles := make(map[string]bool)
@@ -153,6 +134,42 @@ func (s *Status) Execute() error {
 	return nil
 }
 
+// fetchCommittedFiles returns the files of the HEAD commit, with content
+// only for the ones also in localFiles.
+func (s *Status) fetchCommittedFiles(localFiles map[string][]byte) (map[string][]byte, error) {
+	address := s.config.Address()
+	commitHash, _, _, err := s.client.GetFiles(address, "", nil)
+	if err != nil || commitHash == "" {
+		return nil, err
+	}
+
+	entries, err := s.client.Tree(address, commitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	committedFiles := make(map[string][]byte)
+	var paths []string
+	for _, entry := range entries {
+		committedFiles[entry.Path] = nil
+		if _, ok := localFiles[entry.Path]; ok {
+			paths = append(paths, entry.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return committedFiles, nil
+	}
+
+	_, files, _, err := s.client.GetFiles(address, commitHash, paths)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		committedFiles[file.Path] = file.Content
+	}
+	return committedFiles, nil
+}
+
 func getLocalFiles() (map[string][]byte, error) {
 	entries, err := os.ReadDir(".")
 	if err != nil {
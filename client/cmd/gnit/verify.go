@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	config "github.com/gnoverse/gnit"
+	filesystem "github.com/gnoverse/gnit"
+	gnokey "github.com/gnoverse/gnit"
+)
+
+type Verify struct {
+	client *gnokey.Client
+	config *config.Config
+	commit string
+}
+
+func NewVerify(client *gnokey.Client, cfg *config.Config) *Verify {
+	return &Verify{
+		client: client,
+		config: cfg,
+	}
+}
+
+// SetCommit sets the commit hash the files are checked against. Without
+// it, files are checked against HEAD as reported by the node.
+func (v *Verify) SetCommit(commit string) {
+	v.commit = commit
+}
+
+func (v *Verify) Execute(paths []string) error {
+	if err := CheckGnitRepository(); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, path := range paths {
+		commit, err := v.verify(path)
+		if err != nil {
+			fmt.Printf("FAILED  %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("OK      %s (commit %s)\n", path, commit)
+	}
+
+	if v.commit == "" {
+		fmt.Println("\nNote: HEAD was reported by the node; pass --commit <hash> to check against a known commit")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d file(s) failed verification", failed, len(paths))
+	}
+	return nil
+}
+
+func (v *Verify) verify(path string) (string, error) {
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("'%s' is outside the repository", path)
+	}
+	repoPath := filepath.ToSlash(filepath.Clean(path))
+
+	content, mode, err := filesystem.ReadEntry(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	proof, err := v.client.Proof(v.config.Address(), v.commit, repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	commit := v.commit
+	if commit == "" {
+		commit = proof.Commit
+	}
+
+	if err := proof.Verify(repoPath, content, mode, commit); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
@@ -1,35 +1,51 @@
 package client
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path"
 	"strings"
 )
 
+// GnitPackagePath is the import path of the on-chain gnit package.
+const GnitPackagePath = "gno.land/p/demo/gnit"
+
+const DefaultRegistryPath = "gno.land/r/demo/gnit/registry"
+
 type Config struct {
-	RealmPath string
-	Remote    string
-	ChainID   string
-	GasFee    string
-	GasWanted string
-	Account   string
+	RealmPath    string
+	Repository   string // name within the realm's Repositories, or "" for its Repository
+	RegistryPath string
+	Remote       string
+	ChainID      string
+	GasFee       string
+	GasWanted    string
+	Account      string
 }
 
 type GnitFile struct {
 	StagedFiles []string `json:"staged_files"`
+	Realm       string   `json:"realm,omitempty"` // repository address, when not the gnomod.toml module
 }
 
 func DefaultConfig() (*Config, error) {
 	realmPath := readPackagePathFromGnomod()
+	name := ""
+	if address := readAddressFromGnitFile(); address != "" {
+		realmPath, name = SplitAddress(address)
+	}
 
 	return &Config{
-		RealmPath: realmPath,
-		Remote:    "tcp://127.0.0.1:26657",
-		ChainID:   "dev",
-		GasFee:    "10000000ugnot",
-		GasWanted: "5000000000",
-		Account:   "test",
+		RealmPath:    realmPath,
+		Repository:   name,
+		RegistryPath: DefaultRegistryPath,
+		Remote:       "tcp://127.0.0.1:26657",
+		ChainID:      "dev",
+		GasFee:       "10000000ugnot",
+		GasWanted:    "5000000000",
+		Account:      "test",
 	}, nil
 }
 
@@ -44,6 +60,57 @@ func PackageAlias(realmPath string) string {
 	return path.Base(realmPath)
 }
 
+// Address returns the address of the configured repository: the realm path,
+// followed by "#name" for repositories hosted in a Repositories container.
+func (c *Config) Address() string {
+	if c.Repository == "" {
+		return c.RealmPath
+	}
+	return c.RealmPath + "#" + c.Repository
+}
+
+// RepositoryExpr returns the expression of the configured repository in
+// pkg, the import alias or path of its realm.
+func (c *Config) RepositoryExpr(pkg string) string {
+	return RepositoryExpr(pkg, c.Repository)
+}
+
+// SplitAddress splits a repository address such as "gno.land/r/team/hub#api"
+// into its realm path and repository name.
+func SplitAddress(address string) (realmPath, name string) {
+	if i := strings.Index(address, "#"); i >= 0 {
+		return address[:i], address[i+1:]
+	}
+	return address, ""
+}
+
+// RepositoryExpr returns the expression of the repository called name in
+// pkg. Without a name, it is the realm's single Repository variable.
+func RepositoryExpr(pkg, name string) string {
+	if name == "" {
+		return pkg + ".Repository"
+	}
+	return fmt.Sprintf("%s.Repositories.Get(%q)", pkg, name)
+}
+
+// addressExpr returns the expression of the repository at address.
+func addressExpr(address string) string {
+	return RepositoryExpr(SplitAddress(address))
+}
+
+func readAddressFromGnitFile() string {
+	data, err := os.ReadFile(".gnit")
+	if err != nil {
+		return ""
+	}
+
+	var gnitFile GnitFile
+	if err := json.Unmarshal(data, &gnitFile); err != nil {
+		return ""
+	}
+	return gnitFile.Realm
+}
+
 func readPackagePathFromGnomod() string {
 	data, err := os.ReadFile("gnomod.toml")
 	if err != nil {
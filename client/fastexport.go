@@ -0,0 +1,516 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// GitIdentity is an author or committer line of a fast-export stream.
+type GitIdentity struct {
+	Name     string
+	Email    string
+	When     int64
+	Timezone string
+}
+
+// FileOp is a file change of a fast-export commit: 'M' modifies a file,
+// 'D' deletes it and 'A' (deleteall) clears the whole tree.
+type FileOp struct {
+	Kind    byte
+	Mode    FileMode
+	Path    string
+	DataRef string // blob mark or object id; empty for inline data
+	Content []byte // inline data
+}
+
+type FastExportCommit struct {
+	Ref       string
+	Mark      string
+	Author    GitIdentity
+	Committer GitIdentity
+	Message   string
+	From      string
+	Merges    []string
+	Ops       []FileOp
+}
+
+type FastExportStream struct {
+	Blobs   map[string][]byte // mark -> content
+	Commits []*FastExportCommit
+	Resets  map[string]string // ref -> commit it was reset to
+}
+
+// ReplayCommit is a commit of a linearized history, ready to be sent to
+// Repository.ImportCommit.
+type ReplayCommit struct {
+	Message   string
+	Author    GitIdentity
+	Files     map[string][]byte
+	Modes     map[string]FileMode
+	Removed   []string
+	SourceRef string // mark or object id in the source repository
+}
+
+// ParseFastExport reads a stream produced by git fast-export, up to its
+// end or a done command. Tags, progress and feature commands are skipped.
+// A *bufio.Reader is read directly, so the caller can keep reading after
+// done.
+func ParseFastExport(r io.Reader) (*FastExportStream, error) {
+	reader, ok := r.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+
+	p := &fastExportParser{
+		reader: reader,
+		stream: &FastExportStream{Blobs: make(map[string][]byte), Resets: make(map[string]string)},
+	}
+
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.stream, nil
+}
+
+type fastExportParser struct {
+	reader  *bufio.Reader
+	stream  *FastExportStream
+	line    string
+	pending bool
+}
+
+func (p *fastExportParser) next() (string, error) {
+	if p.pending {
+		p.pending = false
+		return p.line, nil
+	}
+
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		if err == io.EOF && line != "" {
+			p.line = line
+			return line, nil
+		}
+		return "", err
+	}
+
+	p.line = strings.TrimSuffix(line, "\n")
+	return p.line, nil
+}
+
+func (p *fastExportParser) unread() {
+	p.pending = true
+}
+
+func (p *fastExportParser) readData(header string) ([]byte, error) {
+	if !strings.HasPrefix(header, "data ") {
+		return nil, fmt.Errorf("expected data command, got %q", header)
+	}
+
+	size, err := strconv.Atoi(strings.TrimPrefix(header, "data "))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported data header %q", header)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(p.reader, data); err != nil {
+		return nil, fmt.Errorf("failed to read %d bytes of data: %w", size, err)
+	}
+
+	if b, err := p.reader.Peek(1); err == nil && b[0] == '\n' {
+		p.reader.ReadByte()
+	}
+
+	return data, nil
+}
+
+func (p *fastExportParser) parse() error {
+	for {
+		line, err := p.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "done":
+			return nil
+		case line == "" || line == "checkpoint":
+		case line == "blob":
+			if err := p.parseBlob(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "commit "):
+			if err := p.parseCommit(strings.TrimPrefix(line, "commit ")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "tag "):
+			if err := p.skipTag(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "reset "):
+			if err := p.parseReset(strings.TrimPrefix(line, "reset ")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "progress "), strings.HasPrefix(line, "feature "),
+			strings.HasPrefix(line, "option "):
+		default:
+			return fmt.Errorf("unsupported fast-export command %q", line)
+		}
+	}
+}
+
+func (p *fastExportParser) parseBlob() error {
+	mark := ""
+	for {
+		line, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case strings.HasPrefix(line, "mark "):
+			mark = strings.TrimPrefix(line, "mark ")
+		case strings.HasPrefix(line, "original-oid "):
+		case strings.HasPrefix(line, "data "):
+			data, err := p.readData(line)
+			if err != nil {
+				return err
+			}
+			p.stream.Blobs[mark] = data
+			return nil
+		default:
+			return fmt.Errorf("unexpected line in blob: %q", line)
+		}
+	}
+}
+
+func (p *fastExportParser) parseReset(ref string) error {
+	line, err := p.next()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(line, "from ") {
+		p.stream.Resets[ref] = strings.TrimPrefix(line, "from ")
+	} else {
+		p.unread()
+	}
+	return nil
+}
+
+func (p *fastExportParser) skipTag() error {
+	for {
+		line, err := p.next()
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "data ") {
+			_, err := p.readData(line)
+			return err
+		}
+	}
+}
+
+func (p *fastExportParser) parseCommit(ref string) error {
+	commit := &FastExportCommit{Ref: ref}
+
+	for {
+		line, err := p.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case strings.HasPrefix(line, "mark "):
+			commit.Mark = strings.TrimPrefix(line, "mark ")
+		case strings.HasPrefix(line, "original-oid "), strings.HasPrefix(line, "encoding "):
+		case strings.HasPrefix(line, "author "):
+			commit.Author, err = parseGitIdentity(strings.TrimPrefix(line, "author "))
+		case strings.HasPrefix(line, "committer "):
+			commit.Committer, err = parseGitIdentity(strings.TrimPrefix(line, "committer "))
+		case strings.HasPrefix(line, "data "):
+			var message []byte
+			message, err = p.readData(line)
+			commit.Message = string(message)
+		case strings.HasPrefix(line, "from "):
+			commit.From = strings.TrimPrefix(line, "from ")
+		case strings.HasPrefix(line, "merge "):
+			commit.Merges = append(commit.Merges, strings.TrimPrefix(line, "merge "))
+		case line == "deleteall":
+			commit.Ops = append(commit.Ops, FileOp{Kind: 'A'})
+		case strings.HasPrefix(line, "M "):
+			var op FileOp
+			op, err = p.parseModify(strings.TrimPrefix(line, "M "))
+			commit.Ops = append(commit.Ops, op)
+		case strings.HasPrefix(line, "D "):
+			var path string
+			path, err = unquoteGitPath(strings.TrimPrefix(line, "D "))
+			commit.Ops = append(commit.Ops, FileOp{Kind: 'D', Path: path})
+		case strings.HasPrefix(line, "N "):
+			err = p.skipNote(line)
+		case line == "":
+			p.stream.Commits = append(p.stream.Commits, commit)
+			return nil
+		default:
+			p.unread()
+			p.stream.Commits = append(p.stream.Commits, commit)
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("commit %s: %w", commit.Mark, err)
+		}
+	}
+
+	p.stream.Commits = append(p.stream.Commits, commit)
+	return nil
+}
+
+func (p *fastExportParser) parseModify(args string) (FileOp, error) {
+	fields := strings.SplitN(args, " ", 3)
+	if len(fields) != 3 {
+		return FileOp{}, fmt.Errorf("invalid modify command %q", args)
+	}
+
+	mode, err := strconv.ParseUint(fields[0], 8, 32)
+	if err != nil {
+		return FileOp{}, fmt.Errorf("invalid mode %q", fields[0])
+	}
+
+	path, err := unquoteGitPath(fields[2])
+	if err != nil {
+		return FileOp{}, err
+	}
+
+	op := FileOp{Kind: 'M', Mode: normalizeGitMode(FileMode(mode)), Path: path}
+	if fields[1] != "inline" {
+		op.DataRef = fields[1]
+		return op, nil
+	}
+
+	line, err := p.next()
+	if err != nil {
+		return FileOp{}, err
+	}
+
+	op.Content, err = p.readData(line)
+	return op, err
+}
+
+func (p *fastExportParser) skipNote(line string) error {
+	if !strings.HasPrefix(line, "N inline ") {
+		return nil
+	}
+
+	data, err := p.next()
+	if err != nil {
+		return err
+	}
+	_, err = p.readData(data)
+	return err
+}
+
+// normalizeGitMode maps legacy git modes such as 100664 to the modes gnit
+// knows about.
+func normalizeGitMode(mode FileMode) FileMode {
+	switch mode {
+	case ModeExecutable, ModeSymlink, ModeSubmodule:
+		return mode
+	}
+	if mode&0111 != 0 {
+		return ModeExecutable
+	}
+	return ModeRegular
+}
+
+func parseGitIdentity(s string) (GitIdentity, error) {
+	open := strings.LastIndex(s, "<")
+	close := strings.LastIndex(s, ">")
+	if open == -1 || close < open {
+		return GitIdentity{}, fmt.Errorf("invalid identity %q", s)
+	}
+
+	id := GitIdentity{
+		Name:  strings.TrimSpace(s[:open]),
+		Email: s[open+1 : close],
+	}
+
+	fields := strings.Fields(s[close+1:])
+	if len(fields) > 0 {
+		when, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return GitIdentity{}, fmt.Errorf("invalid timestamp in %q", s)
+		}
+		id.When = when
+	}
+	if len(fields) > 1 {
+		id.Timezone = fields[1]
+	}
+
+	return id, nil
+}
+
+// unquoteGitPath decodes the C-style quoting git applies to unusual paths.
+func unquoteGitPath(path string) (string, error) {
+	if !strings.HasPrefix(path, "\"") {
+		return path, nil
+	}
+
+	unquoted, err := strconv.Unquote(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted path %s", path)
+	}
+	return unquoted, nil
+}
+
+// Replay linearizes the history ending at the last commit of the stream by
+// following first parents, and returns the commits oldest first with the
+// files each of them changes or removes. Merged side branches are folded
+// into their merge commit, since file changes are relative to the first
+// parent.
+func (s *FastExportStream) Replay() ([]*ReplayCommit, error) {
+	if len(s.Commits) == 0 {
+		return nil, nil
+	}
+	return s.replay(s.Commits[len(s.Commits)-1])
+}
+
+// ReplayRef is like Replay for the history ending at the last commit of the
+// stream on ref. Commits whose first parent is outside the stream start the
+// history; Base reports that parent.
+func (s *FastExportStream) ReplayRef(ref string) ([]*ReplayCommit, error) {
+	for i := len(s.Commits) - 1; i >= 0; i-- {
+		if s.Commits[i].Ref == ref {
+			return s.replay(s.Commits[i])
+		}
+	}
+	return nil, nil
+}
+
+// Base returns the first parent of the oldest replayed commit when it is not
+// part of the stream, such as a mark of an earlier export, or "".
+func (s *FastExportStream) Base(replay []*ReplayCommit) string {
+	if len(replay) == 0 {
+		return ""
+	}
+
+	for _, commit := range s.Commits {
+		if commit.Mark == replay[0].SourceRef {
+			return commit.From
+		}
+	}
+	return ""
+}
+
+func (s *FastExportStream) replay(tip *FastExportCommit) ([]*ReplayCommit, error) {
+	byMark := make(map[string]*FastExportCommit)
+	for _, commit := range s.Commits {
+		if commit.Mark != "" {
+			byMark[commit.Mark] = commit
+		}
+	}
+
+	var chain []*FastExportCommit
+	for commit := tip; commit != nil; commit = byMark[commit.From] {
+		chain = append(chain, commit)
+		if commit.From == "" {
+			break
+		}
+	}
+
+	tree := make(map[string]bool)
+	replay := make([]*ReplayCommit, 0, len(chain))
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		commit := chain[i]
+
+		rc := &ReplayCommit{
+			Message:   strings.TrimRight(commit.Message, "\n"),
+			Author:    commit.Author,
+			Files:     make(map[string][]byte),
+			Modes:     make(map[string]FileMode),
+			SourceRef: commit.Mark,
+		}
+
+		for _, op := range commit.Ops {
+			switch op.Kind {
+			case 'A':
+				for path := range tree {
+					delete(tree, path)
+					rc.remove(path)
+				}
+			case 'D':
+				delete(tree, op.Path)
+				rc.remove(op.Path)
+				for path := range tree {
+					if strings.HasPrefix(path, op.Path+"/") {
+						delete(tree, path)
+						rc.remove(path)
+					}
+				}
+			case 'M':
+				content, err := s.opContent(op)
+				if err != nil {
+					return nil, fmt.Errorf("commit %s: %w", commit.Mark, err)
+				}
+
+				tree[op.Path] = true
+				rc.Files[op.Path] = content
+				rc.Modes[op.Path] = op.Mode
+				rc.unremove(op.Path)
+			}
+		}
+
+		replay = append(replay, rc)
+	}
+
+	return replay, nil
+}
+
+func (s *FastExportStream) opContent(op FileOp) ([]byte, error) {
+	if op.DataRef == "" {
+		return op.Content, nil
+	}
+
+	if op.Mode == ModeSubmodule {
+		return []byte(op.DataRef), nil
+	}
+
+	content, ok := s.Blobs[op.DataRef]
+	if !ok {
+		return nil, fmt.Errorf("unknown blob %s for %s", op.DataRef, op.Path)
+	}
+	return content, nil
+}
+
+func (rc *ReplayCommit) remove(path string) {
+	delete(rc.Files, path)
+	delete(rc.Modes, path)
+	for _, removed := range rc.Removed {
+		if removed == path {
+			return
+		}
+	}
+	rc.Removed = append(rc.Removed, path)
+}
+
+func (rc *ReplayCommit) unremove(path string) {
+	for i, removed := range rc.Removed {
+		if removed == path {
+			rc.Removed = append(rc.Removed[:i], rc.Removed[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// FastImportWriter writes a git fast-import stream. Blobs and commits are
+// keyed by their gnit hash and get a mark the first time they are written.
+type FastImportWriter struct {
+	w        *bufio.Writer
+	nextMark int
+	blobs    map[string]int
+	commits  map[string]int
+}
+
+func NewFastImportWriter(w io.Writer) *FastImportWriter {
+	return &FastImportWriter{
+		w:        bufio.NewWriter(w),
+		nextMark: 1,
+		blobs:    make(map[string]int),
+		commits:  make(map[string]int),
+	}
+}
+
+// HasBlob reports whether the blob hash was already written.
+func (fw *FastImportWriter) HasBlob(hash string) bool {
+	_, ok := fw.blobs[hash]
+	return ok
+}
+
+// HasCommit reports whether the commit hash was already written or set.
+func (fw *FastImportWriter) HasCommit(hash string) bool {
+	_, ok := fw.commits[hash]
+	return ok
+}
+
+// SetCommitMark records that commit hash was written as mark by an earlier
+// stream whose marks the importer loads.
+func (fw *FastImportWriter) SetCommitMark(hash string, mark int) {
+	fw.commits[hash] = mark
+	fw.ReserveMarks(mark)
+}
+
+// ReserveMarks makes new marks start after last, for marks used by another
+// writer of the same marks file.
+func (fw *FastImportWriter) ReserveMarks(last int) {
+	if last >= fw.nextMark {
+		fw.nextMark = last + 1
+	}
+}
+
+// CommitMarks returns the mark of every commit hash written or set.
+func (fw *FastImportWriter) CommitMarks() map[string]int {
+	return fw.commits
+}
+
+func (fw *FastImportWriter) mark() int {
+	mark := fw.nextMark
+	fw.nextMark++
+	return mark
+}
+
+func (fw *FastImportWriter) data(content []byte) {
+	fmt.Fprintf(fw.w, "data %d\n", len(content))
+	fw.w.Write(content)
+	fw.w.WriteString("\n")
+}
+
+// Blob writes a blob unless it was already written.
+func (fw *FastImportWriter) Blob(hash string, content []byte) {
+	if fw.HasBlob(hash) {
+		return
+	}
+
+	mark := fw.mark()
+	fw.blobs[hash] = mark
+	fmt.Fprintf(fw.w, "blob\nmark :%d\n", mark)
+	fw.data(content)
+}
+
+// Commit writes a commit on ref. The changed and removed files are relative
+// to the first parent. The blobs of changed files and the parents of the
+// commit must already be written, except for submodules, whose content is
+// the commit id they point to.
+func (fw *FastImportWriter) Commit(ref string, commit *RemoteCommit, changed []RemoteEntry, removed []string, submodules map[string]string) error {
+	if len(commit.Parents) == 0 {
+		// Without a reset, fast-import would chain a root commit onto the
+		// current tip of ref.
+		fmt.Fprintf(fw.w, "reset %s\n", ref)
+	}
+
+	mark := fw.mark()
+	fw.commits[commit.Hash] = mark
+	fmt.Fprintf(fw.w, "commit %s\nmark :%d\n", ref, mark)
+
+	author := gitIdentityLine(commit.AuthorName, commit.AuthorEmail, commit.AuthorAddress, commit.Timestamp)
+	committer := author
+	if commit.Committer != "" {
+		committer = gitIdentityLine(commit.Committer, "", "", commit.Timestamp)
+	}
+	fmt.Fprintf(fw.w, "author %s\ncommitter %s\n", author, committer)
+
+	message := commit.Message
+	if message != "" && !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	fw.data([]byte(message))
+
+	for i, parent := range commit.Parents {
+		mark, ok := fw.commits[parent]
+		if !ok {
+			return fmt.Errorf("commit %s: parent %s was not written", commit.Hash, parent)
+		}
+
+		if i == 0 {
+			fmt.Fprintf(fw.w, "from :%d\n", mark)
+		} else {
+			fmt.Fprintf(fw.w, "merge :%d\n", mark)
+		}
+	}
+
+	for _, path := range removed {
+		fmt.Fprintf(fw.w, "D %s\n", quoteGitPath(path))
+	}
+
+	for _, entry := range changed {
+		path := quoteGitPath(entry.Path)
+
+		if entry.Mode == ModeSubmodule {
+			fmt.Fprintf(fw.w, "M %o %s %s\n", entry.Mode, submodules[entry.Path], path)
+			continue
+		}
+
+		mark, ok := fw.blobs[entry.Hash]
+		if !ok {
+			return fmt.Errorf("commit %s: blob of %s was not written", commit.Hash, entry.Path)
+		}
+		fmt.Fprintf(fw.w, "M %o :%d %s\n", entry.Mode, mark, path)
+	}
+	fw.w.WriteString("\n")
+
+	return nil
+}
+
+// Reset points ref at a written commit.
+func (fw *FastImportWriter) Reset(ref, commitHash string) error {
+	mark, ok := fw.commits[commitHash]
+	if !ok {
+		return fmt.Errorf("commit %s of %s was not written", commitHash, ref)
+	}
+
+	fmt.Fprintf(fw.w, "reset %s\nfrom :%d\n\n", ref, mark)
+	return nil
+}
+
+// Feature writes a feature command, such as "done" or
+// "export-marks=<path>".
+func (fw *FastImportWriter) Feature(feature string) {
+	fmt.Fprintf(fw.w, "feature %s\n", feature)
+}
+
+// Done ends a stream that declared the done feature.
+func (fw *FastImportWriter) Done() {
+	fw.w.WriteString("done\n")
+}
+
+func (fw *FastImportWriter) Flush() error {
+	return fw.w.Flush()
+}
+
+// gitIdentityLine formats an identity for an author or committer command.
+// On-chain authors without a name are identified by their address.
+func gitIdentityLine(name, email, address string, timestamp int64) string {
+	if name == "" {
+		name = address
+	}
+	name = strings.NewReplacer("<", "", ">", "", "\n", " ").Replace(name)
+	email = strings.NewReplacer("<", "", ">", "", "\n", "").Replace(email)
+
+	if name == "" {
+		return fmt.Sprintf("<%s> %d +0000", email, timestamp)
+	}
+	return fmt.Sprintf("%s <%s> %d +0000", name, email, timestamp)
+}
+
+// quoteGitPath applies the C-style quoting fast-import expects for paths
+// that start with a quote or contain a newline.
+func quoteGitPath(path string) string {
+	if strings.HasPrefix(path, "\"") || strings.ContainsAny(path, "\n") {
+		return strconv.Quote(path)
+	}
+	return path
+}
+
+func isGitObjectID(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !strings.ContainsRune("0123456789abcdef", rune(s[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExportFastImport writes the history reachable from refs to w as a git
+// fast-import stream, parents before children. Each ref is written as
+// refPrefix followed by its name. It returns the number of commits written.
+func (c *Client) ExportFastImport(w io.Writer, address string, refs []RemoteRef, refPrefix string) (int, error) {
+	fw := NewFastImportWriter(w)
+
+	count, err := c.WriteHistory(fw, address, refs, refPrefix)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := fw.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write fast-import stream: %w", err)
+	}
+	return count, nil
+}
+
+// WriteHistory writes the commits reachable from refs that fw has no mark
+// for yet, then points every ref at its commit. The commits are written on
+// the first ref.
+func (c *Client) WriteHistory(fw *FastImportWriter, address string, refs []RemoteRef, refPrefix string) (int, error) {
+	commits := make(map[string]*RemoteCommit)
+	var tips []string
+
+	for _, ref := range refs {
+		tips = append(tips, ref.Hash)
+		if _, ok := commits[ref.Hash]; ok || fw.HasCommit(ref.Hash) {
+			continue
+		}
+
+		log, err := c.Log(address, ref.Hash)
+		if err != nil {
+			return 0, err
+		}
+		for _, commit := range log {
+			if !fw.HasCommit(commit.Hash) {
+				commits[commit.Hash] = commit
+			}
+		}
+	}
+
+	order := topoSortCommits(commits, tips)
+
+	defaultRef := refPrefix + "main"
+	if len(refs) > 0 {
+		defaultRef = refPrefix + refs[0].Name
+	}
+
+	for _, hash := range order {
+		commit := commits[hash]
+
+		changed, removed, err := c.Changes(address, commit.Hash)
+		if err != nil {
+			return 0, err
+		}
+
+		contents, err := c.changedBlobs(fw, address, commit.Hash, changed)
+		if err != nil {
+			return 0, err
+		}
+
+		submodules := make(map[string]string)
+		for _, entry := range changed {
+			content, ok := contents[entry.Path]
+			if !ok {
+				continue
+			}
+
+			if entry.Mode == ModeSubmodule {
+				id := strings.TrimSpace(string(content))
+				if !isGitObjectID(id) {
+					return 0, fmt.Errorf("submodule %s does not point to a git commit", entry.Path)
+				}
+				submodules[entry.Path] = id
+				continue
+			}
+			fw.Blob(entry.Hash, content)
+		}
+
+		if err := fw.Commit(defaultRef, commit, changed, removed, submodules); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, ref := range refs {
+		if err := fw.Reset(refPrefix+ref.Name, ref.Hash); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(order), nil
+}
+
+// changedBlobs returns the content of the changed entries of commitHash
+// that fw has no blob for yet, and of its submodules, by path. They are
+// fetched with GetFiles, a few queries for the whole commit.
+func (c *Client) changedBlobs(fw *FastImportWriter, address, commitHash string, changed []RemoteEntry) (map[string][]byte, error) {
+	var paths []string
+	for _, entry := range changed {
+		if !fw.HasBlob(entry.Hash) || entry.Mode == ModeSubmodule {
+			paths = append(paths, entry.Path)
+		}
+	}
+
+	contents := make(map[string][]byte, len(paths))
+	if len(paths) == 0 {
+		return contents, nil
+	}
+
+	commit, files, missing, err := c.GetFiles(address, commitHash, paths)
+	if err != nil {
+		return nil, err
+	}
+	if commit == "" {
+		return nil, fmt.Errorf("unknown commit %s", commitHash)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("'%s' is missing from commit %s", missing[0], commitHash)
+	}
+
+	for _, file := range files {
+		contents[file.Path] = file.Content
+	}
+	return contents, nil
+}
+
+// topoSortCommits orders the commits reachable from tips so that every
+// commit comes after its parents.
+func topoSortCommits(commits map[string]*RemoteCommit, tips []string) []string {
+	var order []string
+	visited := make(map[string]bool)
+
+	for _, tip := range tips {
+		stack := []string{tip}
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			commit, ok := commits[top]
+			if !ok || visited[top] {
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			pending := false
+			for _, parent := range commit.Parents {
+				if _, known := commits[parent]; known && !visited[parent] {
+					stack = append(stack, parent)
+					pending = true
+				}
+			}
+			if pending {
+				continue
+			}
+
+			visited[top] = true
+			order = append(order, top)
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return order
+}
@@ -1,12 +1,22 @@
 package client
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+type FileMode uint32
+
+const (
+	ModeRegular    FileMode = 0100644
+	ModeExecutable FileMode = 0100755
+	ModeSymlink    FileMode = 0120000
+	ModeSubmodule  FileMode = 0160000
+)
+
 func CollectFiles() (map[string][]byte, error) {
 	files := make(map[string][]byte)
 
@@ -50,7 +60,46 @@ func CollectFiles() (map[string][]byte, error) {
 	return files, nil
 }
 
+// CheckLocalPath returns an error unless writing path stays inside the
+// working directory: path must be relative, must not climb out with "..",
+// and none of its existing parent directories may be a symbolic link.
+// Paths come from the realm, which may hold paths crafted to escape.
+func CheckLocalPath(path string) error {
+	if !filepath.IsLocal(path) || strings.Contains(path, "\\") {
+		return fmt.Errorf("refusing to write '%s' outside the working directory", path)
+	}
+
+	parent := ""
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	for _, part := range parts[:len(parts)-1] {
+		parent = filepath.Join(parent, part)
+
+		info, err := os.Lstat(parent)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("refusing to write '%s' through the symbolic link '%s'", path, parent)
+		}
+	}
+	return nil
+}
+
+// WriteFile writes content to path inside the working directory. An
+// existing symbolic link at path is replaced rather than followed.
 func WriteFile(path string, content []byte) error {
+	if err := CheckLocalPath(path); err != nil {
+		return err
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", path, err)
+		}
+	}
+
 	dir := filepath.Dir(path)
 	if dir != "." && dir != "" {
 		if err := os.MkdirAll(dir, 0755); err != nil {
@@ -64,16 +113,86 @@ func WriteFile(path string, content []byte) error {
 	return nil
 }
 
-func SerializeFiles(files map[string][]byte) string {
+// ReadEntry reads the file at path along with its tree mode. Symbolic
+// links are not followed: their content is the link target.
+func ReadEntry(path string) ([]byte, FileMode, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []byte(target), ModeSymlink, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if info.Mode()&0111 != 0 {
+		return content, ModeExecutable, nil
+	}
+	return content, ModeRegular, nil
+}
+
+// WriteFileMode writes content to path, creating a symbolic link or setting
+// the executable bit according to mode.
+func WriteFileMode(path string, content []byte, mode FileMode) error {
+	if mode != ModeSymlink {
+		if err := WriteFile(path, content); err != nil {
+			return err
+		}
+
+		perm := os.FileMode(0644)
+		if mode == ModeExecutable {
+			perm = 0755
+		}
+		if err := os.Chmod(path, perm); err != nil {
+			return fmt.Errorf("failed to set mode of %s: %w", path, err)
+		}
+		return nil
+	}
+
+	if err := CheckLocalPath(path); err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	if err := os.Symlink(string(content), path); err != nil {
+		return fmt.Errorf("failed to create symlink %s: %w", path, err)
+	}
+	return nil
+}
+
+// SerializeFiles encodes files as "path|mode|base64" lines, the wire format
+// decoded by the generated commit transaction.
+func SerializeFiles(files map[string][]byte, modes map[string]FileMode) string {
 	var builder strings.Builder
 	for filename, content := range files {
-		escaped := strings.ReplaceAll(string(content), "\\", "\\\\")
-		escaped = strings.ReplaceAll(escaped, "\n", "\\n")
-		escaped = strings.ReplaceAll(escaped, "|", "\\|")
+		mode, ok := modes[filename]
+		if !ok {
+			mode = ModeRegular
+		}
 
 		builder.WriteString(filename)
 		builder.WriteString("|")
-		builder.WriteString(escaped)
+		builder.WriteString(fmt.Sprintf("%o", mode))
+		builder.WriteString("|")
+		builder.WriteString(base64.StdEncoding.EncodeToString(content))
 		builder.WriteString("\n")
 	}
 	return builder.String()
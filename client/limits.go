@@ -0,0 +1,163 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Limits mirrors the realm's Limits, with the bytes already stored. A zero
+// limit is disabled.
+type Limits struct {
+	MaxBlobSize        int
+	MaxFilesPerCommit  int
+	MaxRepositoryBytes int
+	StoredBytes        int
+	AllowedPaths       []string
+}
+
+// Limits fetches the limits of the repository at address.
+func (c *Client) Limits(address string) (*Limits, error) {
+	data, err := c.QueryString(fmt.Sprintf("%s.SerializeLimits()", addressExpr(address)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository limits: %w", err)
+	}
+	return ParseLimits(data)
+}
+
+// ParseLimits decodes the output of Repository.SerializeLimits.
+func ParseLimits(data string) (*Limits, error) {
+	limits := &Limits{}
+	values := map[string]*int{
+		"max_blob_size":        &limits.MaxBlobSize,
+		"max_files_per_commit": &limits.MaxFilesPerCommit,
+		"max_repository_bytes": &limits.MaxRepositoryBytes,
+		"stored_bytes":         &limits.StoredBytes,
+	}
+
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.SplitN(line, "|", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid limits line %q", line)
+		}
+
+		if fields[0] == "allowed_path" {
+			limits.AllowedPaths = append(limits.AllowedPaths, fields[1])
+			continue
+		}
+
+		value, ok := values[fields[0]]
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", fields[0], fields[1])
+		}
+		*value = n
+	}
+
+	return limits, nil
+}
+
+// Check reports the first limit files would break if committed in one
+// commit. The repository size is checked as if every file were new, so it
+// may reject content the realm already stores.
+func (l *Limits) Check(files map[string][]byte) error {
+	if l.MaxFilesPerCommit > 0 && len(files) > l.MaxFilesPerCommit {
+		return fmt.Errorf("%d files exceed the limit of %d files per commit", len(files), l.MaxFilesPerCommit)
+	}
+
+	total := 0
+	for path, content := range files {
+		if l.MaxBlobSize > 0 && len(content) > l.MaxBlobSize {
+			return fmt.Errorf("'%s' is %d bytes, the limit is %d", path, len(content), l.MaxBlobSize)
+		}
+		if !l.allows(path) {
+			return fmt.Errorf("'%s' does not match the allowed paths %s", path, strings.Join(l.AllowedPaths, ", "))
+		}
+		total += len(content)
+	}
+
+	if l.MaxRepositoryBytes > 0 && l.StoredBytes+total > l.MaxRepositoryBytes {
+		return fmt.Errorf("the repository would grow to %d bytes, the limit is %d", l.StoredBytes+total, l.MaxRepositoryBytes)
+	}
+	return nil
+}
+
+func (l *Limits) allows(path string) bool {
+	if len(l.AllowedPaths) == 0 {
+		return true
+	}
+	for _, pattern := range l.AllowedPaths {
+		if MatchGlob(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchGlob mirrors the realm's path globs: "*" and "?" do not cross "/",
+// "**" does, and patterns without a "/" match the base name.
+func MatchGlob(pattern, path string) bool {
+	if !strings.Contains(pattern, "/") {
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			path = path[i+1:]
+		}
+	}
+	return matchGlobAt(pattern, path)
+}
+
+func matchGlobAt(pattern, name string) bool {
+	for len(pattern) > 0 {
+		switch {
+		case strings.HasPrefix(pattern, "**"):
+			rest := strings.TrimPrefix(pattern[2:], "/")
+			for i := 0; i <= len(name); i++ {
+				if matchGlobAt(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		case pattern[0] == '*':
+			for i := 0; i <= len(name); i++ {
+				if matchGlobAt(pattern[1:], name[i:]) {
+					return true
+				}
+				if i < len(name) && name[i] == '/' {
+					break
+				}
+			}
+			return false
+		case pattern[0] == '?':
+			if len(name) == 0 || name[0] == '/' {
+				return false
+			}
+		default:
+			if len(name) == 0 || name[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+	return len(name) == 0
+}
+
+// CheckReplay checks commits as replayed one after the other.
+func (l *Limits) CheckReplay(commits []*ReplayCommit) error {
+	replayed := *l
+	for _, commit := range commits {
+		if err := replayed.Check(commit.Files); err != nil {
+			return fmt.Errorf("commit %s: %w", commit.SourceRef, err)
+		}
+		for _, content := range commit.Files {
+			replayed.StoredBytes += len(content)
+		}
+	}
+	return nil
+}
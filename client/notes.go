@@ -0,0 +1,48 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RemoteNote is a commit note as returned by Repository.SerializeNotes.
+type RemoteNote struct {
+	Namespace string
+	Author    string
+	Timestamp int64
+	Text      string
+}
+
+// Notes returns the notes of the commit named by ref at address, in every
+// namespace when namespace is empty.
+func (c *Client) Notes(address, ref, namespace string) ([]RemoteNote, error) {
+	data, err := c.QueryString(fmt.Sprintf("%s.SerializeNotes(%q, %q)", addressExpr(address), ref, namespace))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notes: %w", err)
+	}
+
+	var notes []RemoteNote
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := SplitEscaped(line, '|')
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("invalid note line %q", line)
+		}
+		timestamp, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid note timestamp %q", fields[2])
+		}
+		notes = append(notes, RemoteNote{
+			Namespace: fields[0],
+			Author:    fields[1],
+			Timestamp: timestamp,
+			Text:      fields[3],
+		})
+	}
+
+	return notes, nil
+}
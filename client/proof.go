@@ -0,0 +1,247 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProofEntry is a tree entry of an inclusion proof.
+type ProofEntry struct {
+	Path string
+	Mode FileMode
+	Hash string
+}
+
+// ProofDir is the listing of one directory on the path of a proof. Entries
+// with ModeDir are subdirectories and Path holds their name only.
+type ProofDir struct {
+	Path    string
+	Entries []ProofEntry
+}
+
+// ModeDir is the mode subdirectories are listed with in directory hashes.
+const ModeDir FileMode = 040000
+
+// ProofIdentity is an author or committer of a proven commit.
+type ProofIdentity struct {
+	Name    string
+	Email   string
+	Address string
+}
+
+// Proof is an inclusion proof as returned by Repository.SerializeProof.
+type Proof struct {
+	Commit    string
+	Tree      string
+	Timestamp int64
+	Parents   []string
+	Author    ProofIdentity
+	Committer ProofIdentity
+	Importer  string
+	Message   string
+	File      ProofEntry
+	Dirs      []ProofDir // from the root to the directory of File
+}
+
+// Proof fetches the inclusion proof of path in the commit named by ref. An
+// empty ref means HEAD.
+func (c *Client) Proof(address, ref, path string) (*Proof, error) {
+	data, err := c.QueryString(fmt.Sprintf("%s.SerializeProof(%q, %q)", addressExpr(address), ref, path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch proof of %s: %w", path, err)
+	}
+	if data == "" {
+		return nil, fmt.Errorf("'%s' is not in the tree of %s", path, refName(ref))
+	}
+
+	return ParseProof(data)
+}
+
+func refName(ref string) string {
+	if ref == "" {
+		return "HEAD"
+	}
+	return ref
+}
+
+// ParseProof decodes the output of Repository.SerializeProof.
+func ParseProof(data string) (*Proof, error) {
+	proof := &Proof{}
+	hasFile := false
+
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := SplitEscaped(line, '|')
+		switch {
+		case fields[0] == "commit" && len(fields) == 4:
+			timestamp, err := strconv.ParseInt(fields[3], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp %q", fields[3])
+			}
+			proof.Commit = fields[1]
+			proof.Tree = fields[2]
+			proof.Timestamp = timestamp
+		case fields[0] == "parent" && len(fields) == 2:
+			proof.Parents = append(proof.Parents, fields[1])
+		case fields[0] == "author" && len(fields) == 4:
+			proof.Author = ProofIdentity{Name: fields[1], Email: fields[2], Address: fields[3]}
+		case fields[0] == "committer" && len(fields) == 4:
+			proof.Committer = ProofIdentity{Name: fields[1], Email: fields[2], Address: fields[3]}
+		case fields[0] == "importer" && len(fields) == 2:
+			proof.Importer = fields[1]
+		case fields[0] == "message" && len(fields) == 2:
+			proof.Message = fields[1]
+		case fields[0] == "dir" && len(fields) == 2:
+			proof.Dirs = append(proof.Dirs, ProofDir{Path: fields[1]})
+		case (fields[0] == "file" || fields[0] == "entry") && len(fields) == 4:
+			mode, err := strconv.ParseUint(fields[2], 8, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mode for %s", fields[1])
+			}
+
+			entry := ProofEntry{Path: fields[1], Mode: FileMode(mode), Hash: fields[3]}
+			if fields[0] == "file" {
+				proof.File = entry
+				hasFile = true
+			} else if len(proof.Dirs) == 0 {
+				return nil, fmt.Errorf("proof entry %s outside a directory", entry.Path)
+			} else {
+				dir := &proof.Dirs[len(proof.Dirs)-1]
+				dir.Entries = append(dir.Entries, entry)
+			}
+		default:
+			return nil, fmt.Errorf("invalid proof line %q", line)
+		}
+	}
+
+	if proof.Commit == "" || !hasFile {
+		return nil, fmt.Errorf("incomplete proof")
+	}
+	return proof, nil
+}
+
+// Verify checks that content with the given mode is the file at path, a
+// slash-separated path from the repository root, in commitHash. Every hash
+// is recomputed locally, from the file up through the listing of each
+// directory to the tree and the commit; the hashes claimed by the proof are
+// only compared against.
+func (p *Proof) Verify(path string, content []byte, mode FileMode, commitHash string) error {
+	if p.File.Path != path {
+		return fmt.Errorf("proof is for '%s', not '%s'", p.File.Path, path)
+	}
+	if hash := ObjectHash(content); hash != p.File.Hash {
+		return fmt.Errorf("content hash %s does not match committed blob %s", hash, p.File.Hash)
+	}
+	if mode != p.File.Mode {
+		return fmt.Errorf("mode %o does not match committed mode %o", mode, p.File.Mode)
+	}
+
+	parts := strings.Split(p.File.Path, "/")
+	if len(p.Dirs) != len(parts) {
+		return fmt.Errorf("proof lists %d directories for %s, expected %d", len(p.Dirs), p.File.Path, len(parts))
+	}
+
+	// Walk up from the directory of the file: each listing must hold the
+	// child below it, and its own hash becomes the child of the next one.
+	child := ProofEntry{Path: parts[len(parts)-1], Mode: p.File.Mode, Hash: p.File.Hash}
+	for i := len(p.Dirs) - 1; i >= 0; i-- {
+		dir := p.Dirs[i]
+		if expected := strings.Join(parts[:i], "/"); dir.Path != expected {
+			return fmt.Errorf("proof lists directory %q where %q was expected", dir.Path, expected)
+		}
+
+		hash, err := TreeHash(dir.Entries)
+		if err != nil {
+			return fmt.Errorf("directory %q: %w", dir.Path, err)
+		}
+		if !hasProofEntry(dir.Entries, child) {
+			return fmt.Errorf("directory %q does not list %s with hash %s", dir.Path, child.Path, child.Hash)
+		}
+
+		if i > 0 {
+			child = ProofEntry{Path: parts[i-1], Mode: ModeDir, Hash: hash}
+		} else if hash != p.Tree {
+			return fmt.Errorf("tree hash %s does not match committed tree %s", hash, p.Tree)
+		}
+	}
+
+	if hash := p.CommitHash(); hash != commitHash {
+		return fmt.Errorf("commit hash %s does not match %s", hash, commitHash)
+	}
+	return nil
+}
+
+func hasProofEntry(entries []ProofEntry, entry ProofEntry) bool {
+	for _, e := range entries {
+		if e == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// ObjectHash returns the hash the realm stores content under.
+func ObjectHash(content []byte) string {
+	return hashObject("blob", string(content))
+}
+
+// TreeHash returns the hash of a directory listing the way the realm
+// computes it: subdirectories first, then files, each group sorted by name,
+// one line per entry with its octal mode, length-prefixed name and hash.
+func TreeHash(entries []ProofEntry) (string, error) {
+	sorted := append([]ProofEntry{}, entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if (sorted[i].Mode == ModeDir) != (sorted[j].Mode == ModeDir) {
+			return sorted[i].Mode == ModeDir
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	var listing strings.Builder
+	for i, entry := range sorted {
+		if i > 0 && sorted[i-1].Path == entry.Path {
+			return "", fmt.Errorf("%s is listed twice", entry.Path)
+		}
+		mode := strconv.FormatUint(uint64(entry.Mode), 8)
+		listing.WriteString(mode + " " + strconv.Itoa(len(entry.Path)) + ":" + entry.Path + " " + entry.Hash + "\n")
+	}
+	return hashObject("tree", listing.String()), nil
+}
+
+// CommitHash returns the hash of the proven commit the way the realm
+// computes it, from its fields, each one length-prefixed.
+func (p *Proof) CommitHash() string {
+	var b strings.Builder
+	writeHashField(&b, "tree", p.Tree)
+	for _, parent := range p.Parents {
+		writeHashField(&b, "parent", parent)
+	}
+	writeHashField(&b, "author", p.Author.Name)
+	writeHashField(&b, "email", p.Author.Email)
+	writeHashField(&b, "address", p.Author.Address)
+	writeHashField(&b, "committer", p.Committer.Name)
+	writeHashField(&b, "committer-email", p.Committer.Email)
+	writeHashField(&b, "committer-address", p.Committer.Address)
+	writeHashField(&b, "importer", p.Importer)
+	writeHashField(&b, "timestamp", strconv.FormatInt(p.Timestamp, 10))
+	writeHashField(&b, "message", p.Message)
+	return hashObject("commit", b.String())
+}
+
+func writeHashField(b *strings.Builder, key, value string) {
+	b.WriteString(key + " " + strconv.Itoa(len(value)) + ":" + value + "\n")
+}
+
+// hashObject mirrors the realm's hash: SHA-256 over the kind, the length
+// and the data.
+func hashObject(kind, data string) string {
+	sum := sha256.Sum256([]byte(kind + " " + strconv.Itoa(len(data)) + "\x00" + data))
+	return hex.EncodeToString(sum[:])
+}
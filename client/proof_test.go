@@ -0,0 +1,74 @@
+package client
+
+import "testing"
+
+// testProof builds a proof of path holding content in a tree where every
+// other file is a copy of it under old/.
+func testProof(t *testing.T, path string, content []byte) (*Proof, string) {
+	t.Helper()
+
+	blob := ObjectHash(content)
+	old := []ProofEntry{{Path: "a.txt", Mode: ModeRegular, Hash: blob}}
+	oldHash, err := TreeHash(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := []ProofEntry{
+		{Path: "old", Mode: ModeDir, Hash: oldHash},
+		{Path: "a.txt", Mode: ModeRegular, Hash: blob},
+	}
+	rootHash, err := TreeHash(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof := &Proof{
+		Tree:      rootHash,
+		Timestamp: 1,
+		Author:    ProofIdentity{Address: "g1w3jhxarpv3j8yh6lta047h6lta047h6l4mfnm7"},
+		Committer: ProofIdentity{Name: "test-repo"},
+		Message:   "First",
+		File:      ProofEntry{Path: path, Mode: ModeRegular, Hash: blob},
+		Dirs:      []ProofDir{{Path: "", Entries: root}},
+	}
+	if path == "old/a.txt" {
+		proof.Dirs = append(proof.Dirs, ProofDir{Path: "old", Entries: old})
+	}
+	proof.Commit = proof.CommitHash()
+	return proof, proof.Commit
+}
+
+func TestVerify(t *testing.T) {
+	content := []byte("package main\n")
+
+	for _, path := range []string{"a.txt", "old/a.txt"} {
+		proof, commit := testProof(t, path, content)
+		if err := proof.Verify(path, content, ModeRegular, commit); err != nil {
+			t.Errorf("expected the proof of %s to verify, got %v", path, err)
+		}
+	}
+
+	proof, commit := testProof(t, "a.txt", content)
+	if err := proof.Verify("a.txt", []byte("changed"), ModeRegular, commit); err == nil {
+		t.Error("expected other content to be rejected")
+	}
+	if err := proof.Verify("a.txt", content, ModeRegular, "other"); err == nil {
+		t.Error("expected another commit to be rejected")
+	}
+
+	proof.Author.Address = "g1vfhkyh6lta047h6lta047h6lta047h6l03vdhu"
+	if err := proof.Verify("a.txt", content, ModeRegular, commit); err == nil {
+		t.Error("expected another author address to be rejected")
+	}
+}
+
+func TestVerifyRejectsProofOfAnotherPath(t *testing.T) {
+	content := []byte("package main\n")
+
+	// A node answering for a.txt with the valid proof of an old copy holding
+	// the same bytes.
+	proof, commit := testProof(t, "old/a.txt", content)
+	if err := proof.Verify("a.txt", content, ModeRegular, commit); err == nil {
+		t.Error("expected a proof of another path to be rejected")
+	}
+}
@@ -0,0 +1,285 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// logPageSize is the number of commits fetched per SerializeLog query.
+const logPageSize = 50
+
+// treePageFiles is the number of files fetched per SerializeTree query.
+const treePageFiles = 1000
+
+// RemoteRef is a branch of an on-chain repository.
+type RemoteRef struct {
+	Name string
+	Hash string
+}
+
+// RemoteCommit is a commit as returned by Repository.SerializeLog.
+type RemoteCommit struct {
+	Hash          string
+	Tree          string
+	Parents       []string
+	AuthorName    string
+	AuthorEmail   string
+	AuthorAddress string
+	Committer     string
+	Timestamp     int64
+	Message       string
+}
+
+// RemoteEntry is a file of a commit tree as returned by
+// Repository.SerializeTree and Repository.SerializeChanges.
+type RemoteEntry struct {
+	Path string
+	Mode FileMode
+	Hash string
+	Size int
+}
+
+// ListRefs returns the branches of the repository at address.
+func (c *Client) ListRefs(address string) ([]RemoteRef, error) {
+	data, err := c.QueryString(fmt.Sprintf("%s.SerializeRefs()", addressExpr(address)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	var refs []RemoteRef
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.SplitN(line, "|", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid ref line %q", line)
+		}
+		refs = append(refs, RemoteRef{Name: fields[0], Hash: fields[1]})
+	}
+
+	return refs, nil
+}
+
+// CurrentBranch returns the branch HEAD points to.
+func (c *Client) CurrentBranch(address string) (string, error) {
+	branch, err := c.QueryString(fmt.Sprintf("%s.GetCurrentBranch()", addressExpr(address)))
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	return branch, nil
+}
+
+// Log returns every commit reachable from ref, children before parents.
+// Linear history is paged from the parent of the last commit of each page,
+// which the realm lists without walking the pages before it; past a merge,
+// pages continue by offset.
+func (c *Client) Log(address, ref string) ([]*RemoteCommit, error) {
+	var commits []*RemoteCommit
+
+	offset := 0
+	for {
+		page, err := c.LogPage(address, ref, offset, logPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		commits = append(commits, page...)
+		if len(page) < logPageSize {
+			return commits, nil
+		}
+
+		if offset == 0 && isLinear(page) {
+			last := page[len(page)-1]
+			if len(last.Parents) == 0 {
+				return commits, nil
+			}
+			ref = last.Parents[0]
+			continue
+		}
+		offset += len(page)
+	}
+}
+
+func isLinear(commits []*RemoteCommit) bool {
+	for _, commit := range commits {
+		if len(commit.Parents) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// LogPage returns up to limit commits reachable from ref, skipping the
+// first offset ones.
+func (c *Client) LogPage(address, ref string, offset, limit int) ([]*RemoteCommit, error) {
+	query := fmt.Sprintf("%s.SerializeLog(%q, %d, %d)", addressExpr(address), ref, offset, limit)
+	data, err := c.QueryString(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch log at offset %d: %w", offset, err)
+	}
+
+	var commits []*RemoteCommit
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" {
+			continue
+		}
+
+		commit, err := parseRemoteCommit(line)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
+
+func parseRemoteCommit(line string) (*RemoteCommit, error) {
+	fields := SplitEscaped(line, '|')
+	if len(fields) != 9 {
+		return nil, fmt.Errorf("invalid log line %q", line)
+	}
+
+	timestamp, err := strconv.ParseInt(fields[7], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp for commit %s: %w", fields[0], err)
+	}
+
+	commit := &RemoteCommit{
+		Hash:          fields[0],
+		Tree:          fields[1],
+		AuthorName:    fields[3],
+		AuthorEmail:   fields[4],
+		AuthorAddress: fields[5],
+		Committer:     fields[6],
+		Timestamp:     timestamp,
+		Message:       fields[8],
+	}
+	if fields[2] != "" {
+		commit.Parents = strings.Split(fields[2], ",")
+	}
+
+	return commit, nil
+}
+
+// Tree returns the files of a commit, a page of SerializeTree at a time.
+func (c *Client) Tree(address, commitHash string) ([]RemoteEntry, error) {
+	var entries []RemoteEntry
+	cursor := ""
+	for {
+		data, err := c.QueryString(fmt.Sprintf("%s.SerializeTree(%q, %q, %d)", addressExpr(address), commitHash, cursor, treePageFiles))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch tree of %s: %w", commitHash, err)
+		}
+
+		cursor = ""
+		for _, line := range strings.Split(data, "\n") {
+			if line == "" {
+				continue
+			}
+
+			fields := SplitEscaped(line, '|')
+			if len(fields) == 2 && fields[0] == "next" {
+				cursor = fields[1]
+				continue
+			}
+
+			entry, err := parseRemoteEntry(fields)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tree line %q: %w", line, err)
+			}
+			entries = append(entries, entry)
+		}
+
+		if cursor == "" {
+			return entries, nil
+		}
+	}
+}
+
+// Changes returns the files commitHash added or modified relative to its
+// first parent, and the paths it removed.
+func (c *Client) Changes(address, commitHash string) ([]RemoteEntry, []string, error) {
+	data, err := c.QueryString(fmt.Sprintf("%s.SerializeChanges(%q)", addressExpr(address), commitHash))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch changes of %s: %w", commitHash, err)
+	}
+
+	var changed []RemoteEntry
+	var removed []string
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := SplitEscaped(line, '|')
+		switch {
+		case len(fields) == 2 && fields[0] == "removed":
+			removed = append(removed, fields[1])
+		case len(fields) == 5 && fields[0] == "file":
+			entry, err := parseRemoteEntry(fields[1:])
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid change line %q: %w", line, err)
+			}
+			changed = append(changed, entry)
+		default:
+			return nil, nil, fmt.Errorf("invalid change line %q", line)
+		}
+	}
+
+	return changed, removed, nil
+}
+
+// parseRemoteEntry parses the path, mode, hash and size fields of a
+// SerializeTree line.
+func parseRemoteEntry(fields []string) (RemoteEntry, error) {
+	if len(fields) != 4 {
+		return RemoteEntry{}, fmt.Errorf("expected 4 fields, got %d", len(fields))
+	}
+
+	mode, err := strconv.ParseUint(fields[1], 8, 32)
+	if err != nil {
+		return RemoteEntry{}, fmt.Errorf("invalid mode for %s: %w", fields[0], err)
+	}
+
+	size, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return RemoteEntry{}, fmt.Errorf("invalid size for %s: %w", fields[0], err)
+	}
+
+	return RemoteEntry{
+		Path: fields[0],
+		Mode: FileMode(mode),
+		Hash: fields[2],
+		Size: size,
+	}, nil
+}
+
+// SplitEscaped splits s on sep, honoring the backslash escapes of the
+// realms' serialized formats.
+func SplitEscaped(s string, sep byte) []string {
+	var fields []string
+	var current strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			if s[i] == 'n' {
+				current.WriteByte('\n')
+			} else {
+				current.WriteByte(s[i])
+			}
+		case s[i] == sep:
+			fields = append(fields, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(s[i])
+		}
+	}
+
+	return append(fields, current.String())
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	importBatchBytes   = 64 * 1024
+	importBatchCommits = 25
+)
+
+// BatchReplayCommits groups commits so that each transaction stays under
+// importBatchBytes of file data and importBatchCommits commits. A commit
+// larger than the limit gets a transaction of its own.
+func BatchReplayCommits(commits []*ReplayCommit) [][]*ReplayCommit {
+	var batches [][]*ReplayCommit
+	var current []*ReplayCommit
+	size := 0
+
+	for _, commit := range commits {
+		commitSize := len(commit.Message)
+		for path, content := range commit.Files {
+			commitSize += len(path) + len(content)
+		}
+
+		if len(current) > 0 && (size+commitSize > importBatchBytes || len(current) >= importBatchCommits) {
+			batches = append(batches, current)
+			current = nil
+			size = 0
+		}
+
+		current = append(current, commit)
+		size += commitSize
+	}
+
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// GenerateImportCode returns a transaction replaying commits with
+// Repository.ImportCommit.
+func GenerateImportCode(address string, commits []*ReplayCommit) string {
+	realmPath, name := SplitAddress(address)
+	repository := RepositoryExpr(PackageAlias(realmPath), name)
+
+	var calls strings.Builder
+	for _, commit := range commits {
+		removed := make([]string, 0, len(commit.Removed))
+		for _, path := range commit.Removed {
+			removed = append(removed, fmt.Sprintf("%q", path))
+		}
+
+		fmt.Fprintf(&calls, "\tfiles, modes = decodeFiles(%q)\n", SerializeFiles(commit.Files, commit.Modes))
+		fmt.Fprintf(&calls, "\t%s.ImportCommit(%q, gnit.Identity{Name: %q, Email: %q}, %d, files, modes, []string{%s})\n",
+			repository, commit.Message, commit.Author.Name, commit.Author.Email, commit.Author.When, strings.Join(removed, ", "))
+	}
+
+	return fmt.Sprintf(`package main
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	%q
+	%q
+)
+%s
+func main() {
+	var files map[string][]byte
+	var modes map[string]gnit.FileMode
+
+%s
+	println("Imported %d commit(s)")
+}
+`, GnitPackagePath, realmPath, DecodeFilesFunc, calls.String(), len(commits))
+}
+
+// DecodeFilesFunc is the transaction-side decoder of SerializeFiles.
+// Transactions embedding it must import encoding/base64, strconv, strings
+// and the gnit package.
+const DecodeFilesFunc = `
+func decodeFiles(filesData string) (map[string][]byte, map[string]gnit.FileMode) {
+	lines := strings.Split(filesData, "\n")
+	files := make(map[string][]byte)
+	modes := make(map[string]gnit.FileMode)
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) == 3 {
+			mode, err := strconv.ParseUint(parts[1], 8, 32)
+			if err != nil {
+				panic("invalid mode for " + parts[0])
+			}
+			content, err := base64.StdEncoding.DecodeString(parts[2])
+			if err != nil {
+				panic("invalid content for " + parts[0])
+			}
+			files[parts[0]] = content
+			modes[parts[0]] = gnit.FileMode(mode)
+		}
+	}
+
+	return files, modes
+}
+`